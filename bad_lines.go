@@ -0,0 +1,43 @@
+package pandat
+
+import (
+	"fmt"
+)
+
+// BadLineAction is what to do with a line whose number of fields differs from the header
+type BadLineAction int
+
+const (
+	// BadLineError returns an error with the line number
+	BadLineError BadLineAction = iota
+	// BadLineSkip drops the line
+	BadLineSkip
+	// BadLineWarn drops the line and calls OnBadLine of the option
+	BadLineWarn
+	// BadLinePad pads a short line with empty values and truncates a long line
+	BadLinePad
+)
+
+// handleBadLine applies the action to a record which does not have n fields,
+// nil is returned if the record is dropped
+func handleBadLine(op string, action BadLineAction, onBadLine func(line int, record []string), record []string, n, line int, cause error) ([]string, error) {
+	switch action {
+	case BadLineSkip:
+		return nil, nil
+	case BadLineWarn:
+		if onBadLine != nil {
+			// the record may be reused by the reader, so the callback gets a copy it can keep
+			onBadLine(line, append([]string(nil), record...))
+		}
+		return nil, nil
+	case BadLinePad:
+		fixed := make([]string, n)
+		copy(fixed, record)
+		return fixed, nil
+	default:
+		if cause == nil {
+			cause = fmt.Errorf("line %d: expected %d fields, got %d", line, n, len(record))
+		}
+		return nil, newError(op, -1, "", ErrBadLine, cause)
+	}
+}
@@ -1,6 +1,7 @@
 package pandat
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -29,19 +30,36 @@ func (d *DataFrame[E]) GetByIndex(i int) *Series[E] {
 // row: index of row
 // col: index of column of name of column, type: string | int
 func (d *DataFrame[E]) Val(row int, col any) E {
+	val, err := d.TryVal(row, col)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// TryVal is like Val but returns ErrColumnNotFound or ErrIndexOutOfRange instead of panic
+func (d *DataFrame[E]) TryVal(row int, col any) (E, error) {
 	var series *Series[E]
 	switch c := col.(type) {
 	case int:
+		if c < 0 || c >= len(d.seriess) {
+			return *new(E), newError("DataFrame.Val", row, strconv.Itoa(c), ErrColumnNotFound, nil)
+		}
 		series = d.seriess[c]
 	case string:
-		if c, ok := d.index[c]; ok {
-			series = d.seriess[c]
+		if i, ok := d.index[c]; ok {
+			series = d.seriess[i]
 		} else {
-			panic("pandat.Dataframe.Val::index out of bound: " + fmt.Sprint(col))
+			return *new(E), newError("DataFrame.Val", row, c, ErrColumnNotFound, nil)
 		}
+	default:
+		return *new(E), newError("DataFrame.Val", row, fmt.Sprint(col), ErrColumnNotFound, nil)
 	}
 
-	return series.Get(row)
+	if row < 0 || row >= series.Len() {
+		return *new(E), newError("DataFrame.Val", row, series.name, ErrIndexOutOfRange, nil)
+	}
+	return series.Get(row), nil
 }
 
 // Location Return sub-DataFrame by giving rows and cols
@@ -51,10 +69,23 @@ func (d *DataFrame[E]) Val(row int, col any) E {
 // Location([]int{1, 2, 3, 4, 5}, ":2")
 // Location([]int{1, 2, 3, 4, 5}, nil)
 func (d *DataFrame[E]) Location(rows any, cols any) *DataFrame[E] {
-	var (
-		irows = d.parseLocationExpr(rows, d.NRows())
-		icols = d.parseLocationExpr(cols, d.NCols())
-	)
+	df, err := d.TryLocation(rows, cols)
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// TryLocation is like Location but returns ErrInvalidExpr instead of panic
+func (d *DataFrame[E]) TryLocation(rows any, cols any) (*DataFrame[E], error) {
+	irows, err := d.parseLocationExpr(rows, d.NRows())
+	if err != nil {
+		return nil, err
+	}
+	icols, err := d.parseLocationExpr(cols, d.NCols())
+	if err != nil {
+		return nil, err
+	}
 
 	seriess := make([]*Series[E], 0, len(icols))
 	for i, series := range d.seriess {
@@ -70,8 +101,10 @@ func (d *DataFrame[E]) Location(rows any, cols any) *DataFrame[E] {
 	df := &DataFrame[E]{
 		seriess: seriess,
 	}
-	df.Reindex()
-	return df
+	if err := df.TryReindex(); err != nil {
+		return nil, err
+	}
+	return df, nil
 }
 
 // Series Return the first series or nil if dataframe is empty
@@ -208,32 +241,57 @@ func (d *DataFrame[E]) Rename(renamer map[any]string, inplace bool) *DataFrame[E
 // i: column index
 // series: column values
 func (d *DataFrame[E]) Insert(i int, series *Series[E]) *DataFrame[E] {
-	names := newSet(d.Names()...)
-	if names.Contains(series.name) {
-		panic("pandat.dataframe.insert::duplicate series name: " + series.name)
+	df, err := d.TryInsert(i, series)
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// TryInsert is like Insert but returns ErrIndexOutOfRange, ErrDuplicateColumn or ErrLengthMismatch instead of panic
+func (d *DataFrame[E]) TryInsert(i int, series *Series[E]) (*DataFrame[E], error) {
+	if i < 0 || i > len(d.seriess) {
+		return nil, newError("DataFrame.Insert", -1, series.name, ErrIndexOutOfRange, nil)
+	}
+	if _, ok := d.index[series.name]; ok {
+		return nil, newError("DataFrame.Insert", -1, series.name, ErrDuplicateColumn, nil)
+	}
+	if len(d.seriess) > 0 && d.NRows() != series.Len() {
+		return nil, newError("DataFrame.Insert", -1, series.name, ErrLengthMismatch, nil)
 	}
 
 	seriess := make([]*Series[E], 0, len(d.seriess)+1)
-	seriess = append(d.seriess[:i], series)
+	seriess = append(seriess, d.seriess[:i]...)
+	seriess = append(seriess, series)
 	seriess = append(seriess, d.seriess[i:]...)
 
 	df := &DataFrame[E]{
 		seriess: seriess,
 	}
-	df.Reindex()
-	return df
+	if err := df.TryReindex(); err != nil {
+		return nil, err
+	}
+	return df, nil
 }
 
 // Concat other dataframe
 func (d *DataFrame[E]) Concat(other *DataFrame[E]) *DataFrame[E] {
+	df, err := d.TryConcat(other)
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// TryConcat is like Concat but returns ErrLengthMismatch or ErrDuplicateColumn instead of panic
+func (d *DataFrame[E]) TryConcat(other *DataFrame[E]) (*DataFrame[E], error) {
 	if d.NRows() != other.NRows() {
-		panic("pandat.dataframe.concat: length not match")
+		return nil, newError("DataFrame.Concat", -1, "", ErrLengthMismatch, nil)
 	}
 
-	names := newSet(d.Names()...)
 	for _, name := range other.Names() {
-		if names.Contains(name) {
-			panic("pandat.dataframe.concat: duplicate series name: " + name)
+		if _, ok := d.index[name]; ok {
+			return nil, newError("DataFrame.Concat", -1, name, ErrDuplicateColumn, nil)
 		}
 	}
 
@@ -241,8 +299,10 @@ func (d *DataFrame[E]) Concat(other *DataFrame[E]) *DataFrame[E] {
 	seriess = append(seriess, d.seriess...)
 	seriess = append(seriess, other.seriess...)
 	df := &DataFrame[E]{seriess: seriess}
-	df.Reindex()
-	return df
+	if err := df.TryReindex(); err != nil {
+		return nil, err
+	}
+	return df, nil
 }
 
 // Transpose the dataframe
@@ -272,14 +332,22 @@ func (d *DataFrame[E]) DTypes() []reflect.Kind {
 
 // Reindex this dataframe
 func (d *DataFrame[E]) Reindex() {
+	if err := d.TryReindex(); err != nil {
+		panic(err)
+	}
+}
+
+// TryReindex is like Reindex but returns ErrDuplicateColumn instead of panic
+func (d *DataFrame[E]) TryReindex() error {
 	index := make(map[string]int, len(d.seriess))
 	for i, series := range d.seriess {
+		if _, ok := index[series.name]; ok {
+			return newError("DataFrame.Reindex", -1, series.name, ErrDuplicateColumn, nil)
+		}
 		index[series.name] = i
 	}
 	d.index = index
-	if len(d.index) != len(d.seriess) {
-		panic("pandat.dataframe.Reindex::all series should have different names")
-	}
+	return nil
 }
 
 func (d *DataFrame[E]) Float64() *DataFrame[float64] {
@@ -342,7 +410,16 @@ func (d *DataFrame[E]) Any() *DataFrame[any] {
 	return df
 }
 
-func (d *DataFrame[E]) parseLocationExpr(expr any, maxto int) map[int]struct{} {
+// Typed converts the dataframe into a Frame in which each column is stored in its own narrowest type
+func (d *DataFrame[E]) Typed() *Frame {
+	columns := make([]Column, 0, len(d.seriess))
+	for _, series := range d.seriess {
+		columns = append(columns, typedColumn(series))
+	}
+	return NewFrame(columns...)
+}
+
+func (d *DataFrame[E]) parseLocationExpr(expr any, maxto int) (map[int]struct{}, error) {
 	if expr == nil {
 		expr = ":"
 	}
@@ -357,7 +434,7 @@ func (d *DataFrame[E]) parseLocationExpr(expr any, maxto int) map[int]struct{} {
 	case string:
 		fromto := strings.Split(e, ":")
 		if len(fromto) != 2 {
-			panic("pandat.dataframe.Location::Error cols expr: " + e)
+			return nil, newError("DataFrame.Location", -1, "", ErrInvalidExpr, errors.New(e))
 		}
 		sfrom := fromto[0]
 		if sfrom == "" {
@@ -370,19 +447,21 @@ func (d *DataFrame[E]) parseLocationExpr(expr any, maxto int) map[int]struct{} {
 
 		from, err := strconv.Atoi(sfrom)
 		if err != nil {
-			panic("pandat.dataframe.Location::Error cols expr: " + e)
+			return nil, newError("DataFrame.Location", -1, "", ErrInvalidExpr, err)
 		}
 		to, err := strconv.Atoi(sto)
 		if err != nil {
-			panic("pandat.dataframe.Location::Error cols expr: " + e)
+			return nil, newError("DataFrame.Location", -1, "", ErrInvalidExpr, err)
 		}
 
 		for i := from; i < to; i++ {
 			ret[i] = struct{}{}
 		}
+	default:
+		return nil, newError("DataFrame.Location", -1, "", ErrInvalidExpr, fmt.Errorf("unsupported type %T", expr))
 	}
 
-	return ret
+	return ret, nil
 }
 
 func (d *DataFrame[E]) Copy() *DataFrame[E] {
@@ -394,18 +473,27 @@ func (d *DataFrame[E]) Copy() *DataFrame[E] {
 
 // NewDataFrame Create a dataframe by given seriess
 func NewDataFrame[E any](values ...*Series[E]) *DataFrame[E] {
+	df, err := TryNewDataFrame(values...)
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// TryNewDataFrame is like NewDataFrame but returns ErrLengthMismatch or ErrDuplicateColumn instead of panic
+func TryNewDataFrame[E any](values ...*Series[E]) (*DataFrame[E], error) {
 	seriess := make([]*Series[E], 0, len(values))
 	index := make(map[string]int, len(values))
 	length := 0
 	for i, val := range values {
+		name := val.Name()
 		if i == 0 {
 			length = val.Len()
 		} else if length != val.Len() {
-			panic("pandat.dataframe.NewDataFrame::all seriess must be the same length")
+			return nil, newError("NewDataFrame", -1, name, ErrLengthMismatch, nil)
 		}
-		name := val.Name()
 		if _, ok := index[name]; ok {
-			panic("pandat.dataframe.NewDataFrame::duplicate series name: " + name)
+			return nil, newError("NewDataFrame", -1, name, ErrDuplicateColumn, nil)
 		}
 		seriess = append(seriess, val)
 		index[name] = i
@@ -414,5 +502,5 @@ func NewDataFrame[E any](values ...*Series[E]) *DataFrame[E] {
 	return &DataFrame[E]{
 		seriess,
 		index,
-	}
+	}, nil
 }
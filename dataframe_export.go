@@ -3,23 +3,91 @@ package pandat
 import (
 	"encoding/csv"
 	"fmt"
-	dynamicstruct "github.com/ompluscator/dynamic-struct"
-	"github.com/xitongsys/parquet-go-source/writerfile"
-	"github.com/xitongsys/parquet-go/writer"
+	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xuri/excelize/v2"
 	"io"
 	"os"
-	"reflect"
-	"runtime"
-	"strconv"
+	"time"
 )
 
 type WriteCSVOption struct {
 	Comma   rune
 	UseCRLF bool
+	// Encoding is the text encoding of output, e.g. "gbk", "gb18030", "utf-16le", UTF-8 is used by default
+	Encoding string
+	// WriteBOM writes a byte order mark first, so that Excel recognizes UTF-8 and UTF-16 files
+	WriteBOM bool
 }
 type WriteXlsxOption struct {
+	// Sheet is the name of the sheet to write, "Sheet1" by default
 	Sheet string
+	// StartCell is the top-left cell of the header, "A1" by default
+	StartCell string
+	// HeaderStyle is the style of header cells, e.g. DefaultHeaderStyle(), header cells are not styled if nil
+	HeaderStyle *excelize.Style
+	// AutoFit sets widths of columns by the longest header or value, east asian wide characters count twice
+	AutoFit bool
+	// NumberFormats are number formats of values by column name, e.g. "#,##0.00", "0.0%" or "yyyy-mm-dd"
+	NumberFormats map[string]string
+	// FreezeHeader freezes rows to the header, and the index column if Index is true
+	FreezeHeader bool
+	// AutoFilter adds filter buttons to the header
+	AutoFilter bool
+	// Index writes row numbers as the first column named IndexLabel
+	Index      bool
+	IndexLabel string
+	// MaxRows is the maximum number of rows of values in a sheet written by streaming,
+	// up to the row limit of Excel, more rows are continued in new sheets
+	MaxRows int
+}
+
+// ParquetCompression is the compression codec of parquet pages
+type ParquetCompression int
+
+const (
+	ParquetSnappy ParquetCompression = iota
+	ParquetGzip
+	ParquetZstd
+	ParquetLz4
+	ParquetUncompressed
+)
+
+var parquetCodecs = map[ParquetCompression]parquet.CompressionCodec{
+	ParquetSnappy:       parquet.CompressionCodec_SNAPPY,
+	ParquetGzip:         parquet.CompressionCodec_GZIP,
+	ParquetZstd:         parquet.CompressionCodec_ZSTD,
+	ParquetLz4:          parquet.CompressionCodec_LZ4,
+	ParquetUncompressed: parquet.CompressionCodec_UNCOMPRESSED,
+}
+
+type WriteParquetOption struct {
+	// Compression is the codec of pages, ParquetSnappy by default
+	Compression ParquetCompression
+	// RowGroupSize is the size of a row group in bytes, 128MB by default
+	RowGroupSize int64
+	// PageSize is the size of a page in bytes, 8KB by default
+	PageSize int64
+	// Dictionary enables or disables dictionary encoding by column name, only strings are dictionary encoded by default
+	Dictionary map[string]bool
+	// Metadata is the key-value metadata of the file
+	Metadata map[string]string
+	// Parallelism is the number of goroutines encoding pages, runtime.NumCPU() by default
+	Parallelism int
+}
+
+func firstWriteParquetOption(option []WriteParquetOption) WriteParquetOption {
+	if len(option) == 0 {
+		return WriteParquetOption{}
+	}
+	return option[0]
+}
+
+type WriteOdsOption struct {
+	// Sheet is the name of the sheet to write, "Sheet1" by default
+	Sheet string
+	// Index writes row numbers as the first column named IndexLabel
+	Index      bool
+	IndexLabel string
 }
 
 func (d *DataFrame[E]) ToCsvPath(filepath string, option WriteCSVOption) error {
@@ -31,172 +99,164 @@ func (d *DataFrame[E]) ToCsvPath(filepath string, option WriteCSVOption) error {
 }
 
 func (d *DataFrame[E]) ToCsv(f io.Writer, option WriteCSVOption) error {
-	w := csv.NewWriter(f)
-	w.Comma = option.Comma
+	return writeCsv(f, dataFrameExportColumns(d), option)
+}
+
+// writeCsv writes the header and values of columns, values are formatted by fmt.Sprint
+func writeCsv(f io.Writer, columns exportColumns, option WriteCSVOption) error {
+	ew, err := encodeWriter(f, option.Encoding, option.WriteBOM)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(ew)
+	if option.Comma != 0 {
+		w.Comma = option.Comma
+	}
 	w.UseCRLF = option.UseCRLF
 
-	err := w.Write(d.Names())
+	err = w.Write(columns.names)
 	if err != nil {
 		return err
 	}
-	for _, series := range d.Transpose().seriess {
-		values := make([]string, 0, series.Len())
-		for _, val := range series.Slice() {
-			values = append(values, fmt.Sprint(val))
+	values := make([]string, len(columns.values))
+	for nrow := 0; nrow < columns.nrows; nrow++ {
+		for ncol, value := range columns.values {
+			values[ncol] = fmt.Sprint(value(nrow))
 		}
 		err := w.Write(values)
 		if err != nil {
-			panic(err)
+			return err
 		}
 	}
 
 	w.Flush()
-	return nil
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return ew.Close()
 }
 
-func (d *DataFrame[E]) ToParquetPath(filepath string) error {
-	f, err := os.Create(filepath)
-	if err != nil {
-		return err
+// exportColumns are columns to write, values are read by row number
+// from the storage of each column so that rows are not materialized
+type exportColumns struct {
+	names  []string
+	nrows  int
+	values []func(int) any
+}
+
+// dataFrameExportColumns returns columns of a dataframe to write
+func dataFrameExportColumns[E any](d *DataFrame[E]) exportColumns {
+	columns := exportColumns{names: d.Names(), nrows: d.NRows()}
+	for _, series := range d.seriess {
+		columns.values = append(columns.values, seriesValues(series))
 	}
+	return columns
+}
 
-	return d.ToParquet(f)
-}
-
-func (d *DataFrame[E]) ToParquet(f io.Writer) error {
-	//namer := strings.NewReplacer(
-	//    " ", "_",
-	//    ",", "",
-	//    ";", "",
-	//    "{", "",
-	//    "}", "",
-	//    "(", "",
-	//    ")", "",
-	//    "=", "",
-	//    "\xEF\xBB\xBF", "",
-	//)
-
-	schema := dynamicstruct.NewStruct()
-	for i, name := range d.Names() {
-		fieldName := "C" + strconv.Itoa(i)
-		columnName := name
-		series := d.seriess[i]
-		switch series.DType() {
-		case reflect.Int:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT64, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*int)(nil), tag)
-		case reflect.Int8:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT32, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*int8)(nil), tag)
-		case reflect.Int16:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT32, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*int16)(nil), tag)
-		case reflect.Int32:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT32, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*int32)(nil), tag)
-		case reflect.Int64:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT64, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*int64)(nil), tag)
-		case reflect.Uint:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT64, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*uint)(nil), tag)
-		case reflect.Uint8:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT32, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*uint8)(nil), tag)
-		case reflect.Uint16:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT32, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*uint16)(nil), tag)
-		case reflect.Uint32:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT64, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*uint32)(nil), tag)
-		case reflect.Uint64:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=INT64, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*uint64)(nil), tag)
-		case reflect.Float32:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=FLOAT, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*float32)(nil), tag)
-		case reflect.Float64:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=DOUBLE, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*float64)(nil), tag)
-		case reflect.Bool:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=BOOL, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*bool)(nil), tag)
+// frameExportColumns returns columns of a frame to write, values of known types are read without boxing the whole column
+func frameExportColumns(f *Frame) exportColumns {
+	columns := exportColumns{names: f.Names(), nrows: f.NRows()}
+	for _, column := range f.columns {
+		var values func(int) any
+		switch s := column.(type) {
+		case *Series[int64]:
+			values = seriesValues(s)
+		case *Series[uint64]:
+			values = seriesValues(s)
+		case *Series[float64]:
+			values = seriesValues(s)
+		case *Series[string]:
+			values = seriesValues(s)
+		case *Series[bool]:
+			values = seriesValues(s)
+		case *Series[time.Time]:
+			values = seriesValues(s)
+		case *Series[any]:
+			values = seriesValues(s)
 		default:
-			tag := fmt.Sprintf(`parquet:"name=%s, type=UTF-8, encoding=PLAIN_DICTIONARY, repetitiontype=OPTIONAL"`, columnName)
-			schema.AddField(fieldName, (*string)(nil), tag)
+			values = seriesValues(column.Any())
 		}
+		columns.values = append(columns.values, values)
 	}
+	return columns
+}
 
-	class := schema.Build()
-	w := writerfile.NewWriterFile(f)
-	defer w.Close()
+func seriesValues[E any](s *Series[E]) func(int) any {
+	return func(i int) any {
+		return s.elements[i]
+	}
+}
 
-	pw, err := writer.NewParquetWriter(w, class.New(), int64(runtime.NumCPU()))
-	if err != nil {
-		return err
+// withIndex returns columns with row numbers as the first column named label
+func (c exportColumns) withIndex(label string) exportColumns {
+	return exportColumns{
+		names:  append([]string{label}, c.names...),
+		nrows:  c.nrows,
+		values: append([]func(int) any{func(i int) any { return int64(i) }}, c.values...),
 	}
-	for _, row := range d.Transpose().seriess {
-		recv := class.New()
-		for ncol, val := range row.elements {
-			field := reflect.ValueOf(recv).Elem().FieldByName("C" + strconv.Itoa(ncol))
-			if !field.IsValid() {
-				// should not happen?
-				continue
-			}
-			switch field.Kind() {
-			case reflect.String:
-				v := fmt.Sprint(val)
-				field.Set(reflect.ValueOf(&v))
-			default:
-				v := reflect.New(reflect.TypeOf(val))
-				v.Elem().Set(reflect.ValueOf(val))
-				field.Set(v)
-			}
-		}
-		if err := pw.Write(recv); err != nil {
-			return err
+}
+
+// index returns the position of the column named name, -1 if not found
+func (c exportColumns) index(name string) int {
+	for i, columnName := range c.names {
+		if columnName == name {
+			return i
 		}
 	}
-	if err := pw.WriteStop(); err != nil {
-		return err
-	}
-	return nil
+	return -1
 }
 
-func (d *DataFrame[any]) ToXlsxPath(filepath string, option WriteXlsxOption) error {
+func (d *DataFrame[E]) ToParquetPath(filepath string, option ...WriteParquetOption) error {
 	f, err := os.Create(filepath)
 	if err != nil {
 		return err
 	}
-	return d.ToXlsx(f, option)
+	if err := d.ToParquet(f, option...); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func (d *DataFrame[any]) ToXlsx(f io.Writer, option WriteXlsxOption) error {
-	w := excelize.NewFile()
+// ToParquet writes the dataframe into a parquet file, columns are optional and typed by their dtypes,
+// values of other dtypes are written as strings and null values are written as nulls
+func (d *DataFrame[E]) ToParquet(f io.Writer, option ...WriteParquetOption) error {
+	return writeParquet("DataFrame.ToParquet", f, dataFrameParquetColumns(d), d.NRows(), firstWriteParquetOption(option))
+}
 
-	if option.Sheet == "" {
-		option.Sheet = "Sheet1"
-		w.NewSheet(option.Sheet)
-	} else {
-		w.NewSheet(option.Sheet)
+func (d *DataFrame[E]) ToXlsxPath(filepath string, option WriteXlsxOption) error {
+	w := NewXlsxWriter()
+	if err := w.write(dataFrameExportColumns(d), option); err != nil {
+		return err
 	}
+	return w.Save(filepath)
+}
 
-	// write header
-	header := d.Names()
-	err := w.SetSheetRow(option.Sheet, "A1", &header)
-	if err != nil {
+func (d *DataFrame[E]) ToOdsPath(filepath string, option WriteOdsOption) error {
+	w := NewOdsWriter()
+	if err := w.write(dataFrameExportColumns(d), option); err != nil {
 		return err
 	}
+	return w.Save(filepath)
+}
 
-	// write data
-	for row, values := range d.Values() {
-		vals := values.Slice()
-		err := w.SetSheetRow(option.Sheet, "A"+strconv.Itoa(row+2), &vals)
-		if err != nil {
-			return err
-		}
+// ToOds writes the dataframe into a new OpenDocument spreadsheet, use OdsWriter to write multiple sheets
+func (d *DataFrame[E]) ToOds(f io.Writer, option WriteOdsOption) error {
+	w := NewOdsWriter()
+	if err := w.write(dataFrameExportColumns(d), option); err != nil {
+		return err
 	}
+	_, err := w.WriteTo(f)
+	return err
+}
 
-	err = w.Write(f)
+// ToXlsx writes the dataframe into a new workbook, use XlsxWriter to write multiple sheets
+func (d *DataFrame[E]) ToXlsx(f io.Writer, option WriteXlsxOption) error {
+	w := NewXlsxWriter()
+	if err := w.write(dataFrameExportColumns(d), option); err != nil {
+		return err
+	}
+	_, err := w.WriteTo(f)
 	return err
 }
@@ -1,8 +1,23 @@
 package pandat
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	dynamicstruct "github.com/ompluscator/dynamic-struct"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+	"io"
+	"math"
 	"os"
+	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestToParquetWithInterfaceDataFrame(t *testing.T) {
@@ -52,3 +67,265 @@ func TestToXlsx(t *testing.T) {
 		panic(err)
 	}
 }
+
+func TestToParquetOption(t *testing.T) {
+	df := NewDataFrame(
+		NewSeries[any]("id", int64(1), int64(2), int64(3)),
+		NewSeries[any]("name", "x", "y", "x"),
+		NewSeries[any]("amount", 1.5, 2.5, 3.5),
+	)
+	codecs := map[ParquetCompression]parquet.CompressionCodec{
+		ParquetSnappy:       parquet.CompressionCodec_SNAPPY,
+		ParquetGzip:         parquet.CompressionCodec_GZIP,
+		ParquetZstd:         parquet.CompressionCodec_ZSTD,
+		ParquetLz4:          parquet.CompressionCodec_LZ4,
+		ParquetUncompressed: parquet.CompressionCodec_UNCOMPRESSED,
+	}
+	for compression, codec := range codecs {
+		filepath := t.TempDir() + "/1.parquet"
+		err := df.ToParquetPath(filepath, WriteParquetOption{
+			Compression:  compression,
+			RowGroupSize: 1 << 20,
+			PageSize:     1 << 10,
+			Dictionary:   map[string]bool{"id": true, "name": false},
+			Metadata:     map[string]string{"source": "test", "version": "1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ReadParquetPath(filepath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Get("name").Slice(), []any{"x", "y", "x"}) || !reflect.DeepEqual(got.Get("amount").Slice(), []any{1.5, 2.5, 3.5}) {
+			t.Fatalf("%v: unexpected dataframe: %v", codec, got)
+		}
+
+		f, err := local.NewLocalFileReader(filepath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetReader(f, nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		metadata := map[string]string{}
+		for _, kv := range pr.Footer.KeyValueMetadata {
+			metadata[kv.Key] = *kv.Value
+		}
+		if !reflect.DeepEqual(metadata, map[string]string{"source": "test", "version": "1"}) {
+			t.Fatalf("unexpected metadata: %v", metadata)
+		}
+		columns := pr.Footer.RowGroups[0].Columns
+		if columns[0].MetaData.Codec != codec {
+			t.Fatalf("expected %v, got %v", codec, columns[0].MetaData.Codec)
+		}
+		dictionary := func(i int) bool {
+			for _, encoding := range columns[i].MetaData.Encodings {
+				if encoding == parquet.Encoding_PLAIN_DICTIONARY || encoding == parquet.Encoding_RLE_DICTIONARY {
+					return true
+				}
+			}
+			return false
+		}
+		if !dictionary(0) || dictionary(1) || dictionary(2) {
+			t.Fatalf("unexpected dictionary encodings: %v %v %v", columns[0].MetaData.Encodings, columns[1].MetaData.Encodings, columns[2].MetaData.Encodings)
+		}
+		pr.ReadStop()
+		_ = f.Close()
+	}
+
+	if err := df.ToParquet(io.Discard, WriteParquetOption{Dictionary: map[string]bool{"missing": true}}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestToParquetColumns(t *testing.T) {
+	day := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	df := NewDataFrame(
+		NewSeries[any]("a _=(1)", int8(1), nil, int8(3)),
+		NewSeries[any]("amount", float32(1.5), float32(2.5), float32(3.5)),
+		NewSeries[any]("count", uint32(1), uint32(2), uint32(3)),
+		NewSeries[any]("ok", true, nil, false),
+		NewSeries[any]("mixed", 1, "x", math.NaN()),
+		NewSeries[any]("big", uint64(math.MaxUint64), nil, uint64(1)),
+		NewSeries[any]("day", day, nil, day.Add(time.Microsecond)),
+	)
+	var buf bytes.Buffer
+	if err := df.ToParquet(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadParquet(buffer.NewBufferFileFromBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]any{
+		"a _=(1)": {int32(1), nil, int32(3)},
+		"amount":  {float32(1.5), float32(2.5), float32(3.5)},
+		"count":   {uint32(1), uint32(2), uint32(3)},
+		"ok":      {true, nil, false},
+		"mixed":   {"1", "x", nil},
+		"big":     {uint64(math.MaxUint64), nil, uint64(1)},
+		"day":     {day, nil, day.Add(time.Microsecond)},
+	}
+	if !reflect.DeepEqual(got.Names(), df.Names()) {
+		t.Fatalf("unexpected names: %v", got.Names())
+	}
+	for name, values := range expected {
+		if !reflect.DeepEqual(got.Get(name).Slice(), values) {
+			t.Fatalf("unexpected %s: %v", name, got.Get(name).Slice())
+		}
+	}
+
+	// typed columns of a frame are written in batches and row groups
+	nrows := parquetBatchRows*2 + 10
+	ids, names := make([]int64, nrows), make([]string, nrows)
+	for i := range ids {
+		ids[i], names[i] = int64(i), strconv.Itoa(i%7)
+	}
+	f := NewFrame(NewSeries("id", ids...), NewSeries("name", names...))
+	buf.Reset()
+	if err := f.ToParquet(&buf, WriteParquetOption{RowGroupSize: 1 << 16, Parallelism: 3}); err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(buf.Bytes()), nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pr.Footer.RowGroups) < 2 || pr.GetNumRows() != int64(nrows) {
+		t.Fatalf("unexpected row groups %d of %d rows", len(pr.Footer.RowGroups), pr.GetNumRows())
+	}
+	pr.ReadStop()
+	got, err = ReadParquet(buffer.NewBufferFileFromBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, parquetBatchRows - 1, parquetBatchRows, nrows - 1} {
+		if got.Val(i, "id") != int64(i) || got.Val(i, "name") != names[i] {
+			t.Fatalf("unexpected row %d: %v %v", i, got.Val(i, "id"), got.Val(i, "name"))
+		}
+	}
+	if err := f.ToParquet(io.Discard, WriteParquetOption{Dictionary: map[string]bool{"missing": true}}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+
+	buf.Reset()
+	if err := NewFrame(NewSeries("big", uint64(math.MaxUint64)), NewSeries("day", day)).ToParquet(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = ReadParquet(buffer.NewBufferFileFromBytes(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if got.Val(0, "big") != uint64(math.MaxUint64) || got.Val(0, "day") != day {
+		t.Fatalf("unexpected typed columns: %v %v", got.Val(0, "big"), got.Val(0, "day"))
+	}
+}
+
+func BenchmarkToParquet(b *testing.B) {
+	df := benchmarkParquetDataFrame()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := df.ToParquet(io.Discard, WriteParquetOption{Parallelism: 1}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkToParquetRows measures the former row based writer as a reference for BenchmarkToParquet
+func BenchmarkToParquetRows(b *testing.B) {
+	df := benchmarkParquetDataFrame()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := toParquetRows(df, io.Discard, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkParquetDataFrame returns 20 columns x 10000 rows of int64, float64, string and boxed float64 values
+func benchmarkParquetDataFrame() *DataFrame[any] {
+	const nrows = 10000
+	var (
+		ids     = make([]int64, nrows)
+		amounts = make([]float64, nrows)
+		names   = make([]string, nrows)
+		mixed   = make([]any, nrows)
+	)
+	for i := 0; i < nrows; i++ {
+		ids[i] = int64(i)
+		amounts[i] = float64(i) / 3
+		names[i] = "name" + strconv.Itoa(i%100)
+		mixed[i] = float64(i)
+	}
+	var seriess []*Series[any]
+	for i := 0; i < 5; i++ {
+		seriess = append(seriess,
+			NewSeries("id"+strconv.Itoa(i), ids...).Any(),
+			NewSeries("amount"+strconv.Itoa(i), amounts...).Any(),
+			NewSeries("name"+strconv.Itoa(i), names...).Any(),
+			NewSeries("mixed"+strconv.Itoa(i), mixed...),
+		)
+	}
+	return NewDataFrame(seriess...)
+}
+
+// toParquetRows is the former ToParquet which builds a dynamic struct per row from the transposed dataframe,
+// it is kept to compare with the column based writer only
+func toParquetRows(d *DataFrame[any], f io.Writer, parallelism int) error {
+	schema := dynamicstruct.NewStruct()
+	for i, name := range d.Names() {
+		var (
+			fieldName = "C" + strconv.Itoa(i)
+			field     any
+			typ       string
+			encoding  string
+		)
+		switch d.seriess[i].DType() {
+		case reflect.Int64:
+			field, typ = (*int64)(nil), "INT64"
+		case reflect.Float64:
+			field, typ = (*float64)(nil), "DOUBLE"
+		case reflect.Bool:
+			field, typ = (*bool)(nil), "BOOL"
+		default:
+			field, typ, encoding = (*string)(nil), "BYTE_ARRAY, convertedtype=UTF8", "PLAIN_DICTIONARY"
+		}
+		tag := fmt.Sprintf(`parquet:"name=%s, type=%s, repetitiontype=OPTIONAL"`, name, typ)
+		if encoding != "" {
+			tag = fmt.Sprintf(`parquet:"name=%s, type=%s, encoding=%s, repetitiontype=OPTIONAL"`, name, typ, encoding)
+		}
+		schema.AddField(fieldName, field, tag)
+	}
+
+	class := schema.Build()
+	w := writerfile.NewWriterFile(f)
+	defer w.Close()
+
+	pw, err := writer.NewParquetWriter(w, class.New(), int64(parallelism))
+	if err != nil {
+		return err
+	}
+	pw.CompressionType = parquetCodecs[WriteParquetOption{}.Compression]
+	for _, row := range d.Transpose().seriess {
+		recv := class.New()
+		for ncol, val := range row.elements {
+			field := reflect.ValueOf(recv).Elem().FieldByName("C" + strconv.Itoa(ncol))
+			switch field.Kind() {
+			case reflect.String:
+				v := fmt.Sprint(val)
+				field.Set(reflect.ValueOf(&v))
+			default:
+				v := reflect.New(reflect.TypeOf(val))
+				v.Elem().Set(reflect.ValueOf(val))
+				field.Set(v)
+			}
+		}
+		if err := pw.Write(recv); err != nil {
+			return err
+		}
+	}
+	return pw.WriteStop()
+}
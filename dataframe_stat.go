@@ -0,0 +1,177 @@
+package pandat
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+)
+
+// Corr returns the pairwise correlation matrix of numeric columns,
+// the i-th row and the i-th column of the result are both the i-th numeric column.
+// Null values are excluded pair by pair.
+func (d *DataFrame[E]) Corr(method CorrMethod) *DataFrame[float64] {
+	return d.pairwise(func(x, y *Series[E]) float64 {
+		return x.Corr(y, method)
+	})
+}
+
+// Cov returns the pairwise sample covariance matrix of numeric columns,
+// the i-th row and the i-th column of the result are both the i-th numeric column.
+// Null values are excluded pair by pair.
+func (d *DataFrame[E]) Cov() *DataFrame[float64] {
+	return d.pairwise(func(x, y *Series[E]) float64 {
+		return x.Cov(y)
+	})
+}
+
+func (d *DataFrame[E]) pairwise(fn func(x, y *Series[E]) float64) *DataFrame[float64] {
+	numerics := make([]*Series[E], 0, len(d.seriess))
+	for _, series := range d.seriess {
+		if series.isNumeric() {
+			numerics = append(numerics, series)
+		}
+	}
+
+	matrix := make([][]float64, len(numerics))
+	for i := range numerics {
+		matrix[i] = make([]float64, len(numerics))
+	}
+	for i, x := range numerics {
+		for j := i; j < len(numerics); j++ {
+			val := fn(x, numerics[j])
+			matrix[i][j] = val
+			matrix[j][i] = val
+		}
+	}
+
+	seriess := make([]*Series[float64], 0, len(numerics))
+	for i, series := range numerics {
+		seriess = append(seriess, NewSeries(series.name, matrix[i]...))
+	}
+	return NewDataFrame(seriess...)
+}
+
+// NLargest returns the first n rows ordered by columns in descending order.
+// Rows with null values in the columns are ignored, the earlier row is kept for equal values.
+func (d *DataFrame[E]) NLargest(n int, columns ...string) *DataFrame[E] {
+	df, err := d.TryNLargest(n, columns...)
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// TryNLargest is like NLargest but returns ErrColumnNotFound or ErrConversion instead of panic
+func (d *DataFrame[E]) TryNLargest(n int, columns ...string) (*DataFrame[E], error) {
+	return d.nOrdered("DataFrame.NLargest", n, columns, false)
+}
+
+// NSmallest returns the first n rows ordered by columns in ascending order.
+// Rows with null values in the columns are ignored, the earlier row is kept for equal values.
+func (d *DataFrame[E]) NSmallest(n int, columns ...string) *DataFrame[E] {
+	df, err := d.TryNSmallest(n, columns...)
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// TryNSmallest is like NSmallest but returns ErrColumnNotFound or ErrConversion instead of panic
+func (d *DataFrame[E]) TryNSmallest(n int, columns ...string) (*DataFrame[E], error) {
+	return d.nOrdered("DataFrame.NSmallest", n, columns, true)
+}
+
+// nOrdered selects the top n rows with a bounded heap, which costs O(rows * log(n)) instead of a full sort
+func (d *DataFrame[E]) nOrdered(op string, n int, columns []string, ascending bool) (*DataFrame[E], error) {
+	if len(columns) == 0 {
+		return nil, newError(op, -1, "", ErrColumnNotFound, errors.New("columns are required"))
+	}
+
+	seriess := make([]*Series[E], 0, len(columns))
+	for _, column := range columns {
+		series := d.Get(column)
+		if series == nil {
+			return nil, newError(op, -1, column, ErrColumnNotFound, nil)
+		}
+		seriess = append(seriess, series)
+	}
+
+	keys := make([][]float64, 0, d.NRows())
+	rows := make([]int, 0, d.NRows())
+	for row := 0; row < d.NRows(); row++ {
+		key := make([]float64, 0, len(columns))
+		for _, series := range seriess {
+			val := series.Get(row)
+			v, ok := nullableFloat64(val)
+			if !ok {
+				if !isNull(val) {
+					return nil, newError(op, row, series.name, ErrConversion, fmt.Errorf("%v is not numeric", val))
+				}
+				key = nil
+				break
+			}
+			key = append(key, v)
+		}
+		if key != nil {
+			keys = append(keys, key)
+			rows = append(rows, row)
+		}
+	}
+
+	h := &rowHeap{keys: keys, ascending: ascending}
+	for i := range keys {
+		if n <= 0 {
+			break
+		}
+		if h.Len() < n {
+			heap.Push(h, i)
+		} else if h.better(i, h.items[0]) {
+			h.items[0] = i
+			heap.Fix(h, 0)
+		}
+	}
+
+	// pop the worst row first and fill from the end
+	selected := make([]int, h.Len())
+	for i := len(selected) - 1; i >= 0; i-- {
+		selected[i] = rows[heap.Pop(h).(int)]
+	}
+
+	taken := make([]*Series[E], 0, len(d.seriess))
+	for _, series := range d.seriess {
+		taken = append(taken, series.take(selected))
+	}
+	return NewDataFrame(taken...), nil
+}
+
+// rowHeap keeps the worst row at the top so that it can be replaced by a better one
+type rowHeap struct {
+	keys      [][]float64
+	items     []int
+	ascending bool
+}
+
+// better reports whether row i is ranked before row j
+func (h *rowHeap) better(i, j int) bool {
+	for k, left := range h.keys[i] {
+		right := h.keys[j][k]
+		if left == right {
+			continue
+		}
+		if h.ascending {
+			return left < right
+		}
+		return left > right
+	}
+	return i < j
+}
+
+func (h *rowHeap) Len() int           { return len(h.items) }
+func (h *rowHeap) Less(i, j int) bool { return h.better(h.items[j], h.items[i]) }
+func (h *rowHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *rowHeap) Push(x any)         { h.items = append(h.items, x.(int)) }
+func (h *rowHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
@@ -0,0 +1,59 @@
+package pandat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCorrAndCov(t *testing.T) {
+	df := ReadMap(map[string][]string{
+		"a": {"1", "2", "3", "4", ""},
+		"b": {"2", "4", "6", "8", "10"},
+		"c": {"x", "y", "z", "w", "v"},
+	})
+
+	corr := df.Corr(Pearson)
+	if ncols := corr.NCols(); ncols != 2 {
+		t.Fatalf("expected 2 numeric columns, got %d", ncols)
+	}
+	if v := corr.Val(corr.Index("a"), "b"); math.Abs(v-1) > 1e-9 {
+		t.Fatalf("expected corr(a, b) = 1, got %v", v)
+	}
+
+	cov := df.Cov()
+	// the last row is dropped for the pair (a, b)
+	if v := cov.Val(cov.Index("a"), "b"); math.Abs(v-10.0/3) > 1e-9 {
+		t.Fatalf("expected cov(a, b) = 10/3, got %v", v)
+	}
+}
+
+func TestNLargest(t *testing.T) {
+	df := NewDataFrame(
+		NewSeries[any]("name", "a", "b", "c", "d", "e", "f"),
+		NewSeries[any]("score", 90, 70, 90, nil, 100, 80),
+		NewSeries[any]("age", 20, 30, 10, 40, 50, 60),
+	)
+
+	top := df.NLargest(3, "score")
+	if names := top.Get("name").Slice(); len(names) != 3 || names[0] != "e" || names[1] != "a" || names[2] != "c" {
+		t.Fatalf("unexpected nlargest: %v", names)
+	}
+
+	top = df.NLargest(2, "score", "age")
+	if names := top.Get("name").Slice(); names[0] != "e" || names[1] != "a" {
+		t.Fatalf("unexpected nlargest: %v", names)
+	}
+
+	bottom := df.NSmallest(10, "score")
+	if names := bottom.Get("name").Slice(); len(names) != 5 || names[0] != "b" || names[4] != "e" {
+		t.Fatalf("unexpected nsmallest: %v", names)
+	}
+
+	if _, err := df.TryNLargest(3, "missing"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+	if _, err := df.TryNSmallest(3, "name"); !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package pandat
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -13,3 +14,37 @@ func TestDropColumn(t *testing.T) {
 	df = df.DropColumn(0, true)
 	fmt.Println(df)
 }
+
+func TestTryErrors(t *testing.T) {
+	df := NewDataFrame(
+		NewSeries("a", 1, 2, 3),
+		NewSeries("b", 4, 5, 6),
+	)
+
+	if _, err := df.TryVal(0, "c"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+	if _, err := df.TryVal(3, "a"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := df.TryLocation("1-2", nil); !errors.Is(err, ErrInvalidExpr) {
+		t.Fatalf("expected ErrInvalidExpr, got %v", err)
+	}
+	if _, err := df.TryInsert(0, NewSeries("a", 1, 2, 3)); !errors.Is(err, ErrDuplicateColumn) {
+		t.Fatalf("expected ErrDuplicateColumn, got %v", err)
+	}
+	if _, err := df.TryConcat(NewDataFrame(NewSeries("c", 1))); !errors.Is(err, ErrLengthMismatch) {
+		t.Fatalf("expected ErrLengthMismatch, got %v", err)
+	}
+	if _, err := TryNewDataFrame(NewSeries("a", 1), NewSeries("a", 2)); !errors.Is(err, ErrDuplicateColumn) {
+		t.Fatalf("expected ErrDuplicateColumn, got %v", err)
+	}
+
+	inserted, err := df.TryInsert(1, NewSeries("c", 7, 8, 9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := inserted.Names(); names[1] != "c" || df.NCols() != 2 || df.Name(1) != "b" {
+		t.Fatalf("unexpected insert result: %v, original: %v", names, df.Names())
+	}
+}
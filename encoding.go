@@ -0,0 +1,148 @@
+package pandat
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodingAuto detects encoding of input by byte order mark and content,
+// input which is neither UTF-16 nor valid UTF-8 is read as GB18030
+const EncodingAuto = "auto"
+
+// detectSize is the number of bytes used to detect encoding
+const detectSize = 4096
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// lookupEncoding returns encoding of given name, e.g. "utf-8", "gbk", "gb18030", "utf-16le", "big5", "shift_jis",
+// nil is returned for UTF-8.
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8":
+		return nil, nil
+	case "utf-16", "utf16":
+		// endianness is determined by byte order mark, little endian by default
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, errors.New("pandat: unsupported encoding: " + name)
+	}
+	if enc == unicode.UTF8 {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+// decodeReader returns a reader which transcodes r from given encoding into UTF-8 and strips the byte order mark
+func decodeReader(r io.Reader, name string) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, detectSize)
+	if name == EncodingAuto {
+		sample, err := br.Peek(detectSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		name = detectEncoding(sample)
+	}
+
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	if enc != nil {
+		br = bufio.NewReader(transform.NewReader(br, enc.NewDecoder()))
+	}
+
+	// a byte order mark is decoded as U+FEFF in UTF-8
+	if prefix, _ := br.Peek(len(utf8BOM)); bytes.Equal(prefix, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	return br, nil
+}
+
+// detectEncoding guesses encoding of the sample by byte order mark, NUL bytes of UTF-16 and validity of UTF-8
+func detectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		return "utf-8"
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+
+	// ASCII characters in UTF-16 have a NUL byte in high order
+	evenNul, oddNul := 0, 0
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenNul++
+		} else {
+			oddNul++
+		}
+	}
+	if pairs := len(sample) / 2; pairs > 0 {
+		if oddNul > pairs/4 && evenNul == 0 {
+			return "utf-16le"
+		}
+		if evenNul > pairs/4 && oddNul == 0 {
+			return "utf-16be"
+		}
+	}
+
+	// the sample may end in the middle of a character
+	for i := 0; i < utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.Valid(sample[:len(sample)-i]) {
+			return "utf-8"
+		}
+	}
+	return "gb18030"
+}
+
+// encodeWriter returns a writer which transcodes UTF-8 into given encoding, the writer must be closed to flush.
+// If bom is true, a byte order mark is written first, which is needed by Excel to recognize UTF-8 and UTF-16.
+func encodeWriter(w io.Writer, name string, bom bool) (io.WriteCloser, error) {
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(name) {
+	case "utf-16", "utf16":
+		// the byte order mark is written below only if required
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	}
+
+	var ew io.WriteCloser
+	if enc == nil {
+		ew = nopWriteCloser{w}
+	} else {
+		ew = transform.NewWriter(w, enc.NewEncoder())
+	}
+	if bom {
+		// U+FEFF is encoded as the byte order mark of the target encoding
+		if _, err := ew.Write(utf8BOM); err != nil {
+			return nil, errors.New("pandat: encoding " + name + " does not support byte order mark")
+		}
+	}
+	return ew, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package pandat
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrColumnNotFound is returned when a column name or column index does not exist
+	ErrColumnNotFound = errors.New("column not found")
+	// ErrIndexOutOfRange is returned when a row index or a column index is out of range
+	ErrIndexOutOfRange = errors.New("index out of range")
+	// ErrLengthMismatch is returned when seriess of a dataframe have different lengths
+	ErrLengthMismatch = errors.New("length mismatch")
+	// ErrDuplicateColumn is returned when a dataframe would contain two columns with the same name
+	ErrDuplicateColumn = errors.New("duplicate column")
+	// ErrConversion is returned when a value can not be converted into the target type
+	ErrConversion = errors.New("conversion failed")
+	// ErrInvalidExpr is returned when a location expression or a cell range can not be parsed
+	ErrInvalidExpr = errors.New("invalid expression")
+	// ErrBadLine is returned when a line of a file has a different number of fields from the header
+	ErrBadLine = errors.New("bad line")
+	// ErrNameNotFound is returned when a sheet, a table or a defined name does not exist in a workbook
+	ErrNameNotFound = errors.New("name not found")
+	// ErrStreamedSheet is returned when a sheet written by streaming is modified, the stream replaces the sheet
+	ErrStreamedSheet = errors.New("sheet written by streaming")
+	// ErrInvalidOption is returned when an option or an argument has an unknown value, e.g. an unknown conditional format type or rank method
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// Error describes where an error happened, use errors.Is to check the kind of Err
+type Error struct {
+	// Op is the operation that failed, e.g. "DataFrame.Val"
+	Op string
+	// Row is the row index, -1 if the error is not related to a row
+	Row int
+	// Column is the column name, empty if the error is not related to a column
+	Column string
+	// Err is one of the sentinel errors of this package
+	Err error
+	// Cause is the underlying error, may be nil
+	Cause error
+}
+
+func (e *Error) Error() string {
+	buf := new(strings.Builder)
+	buf.WriteString("pandat: ")
+	buf.WriteString(e.Op)
+	if e.Column != "" {
+		buf.WriteString(": column ")
+		buf.WriteString(strconv.Quote(e.Column))
+	}
+	if e.Row >= 0 {
+		buf.WriteString(": row ")
+		buf.WriteString(strconv.Itoa(e.Row))
+	}
+	buf.WriteString(": ")
+	buf.WriteString(e.Err.Error())
+	if e.Cause != nil {
+		buf.WriteString(": ")
+		buf.WriteString(e.Cause.Error())
+	}
+	return buf.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the cause matches target, Err is matched by errors.Is through Unwrap
+func (e *Error) Is(target error) bool {
+	return e.Cause != nil && errors.Is(e.Cause, target)
+}
+
+// As finds the first error in the chain of the cause that matches target
+func (e *Error) As(target any) bool {
+	return e.Cause != nil && errors.As(e.Cause, target)
+}
+
+func newError(op string, row int, column string, err error, cause error) *Error {
+	return &Error{
+		Op:     op,
+		Row:    row,
+		Column: column,
+		Err:    err,
+		Cause:  cause,
+	}
+}
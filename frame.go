@@ -0,0 +1,387 @@
+package pandat
+
+import (
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"time"
+)
+
+// Column is the common interface of typed series stored side by side in a Frame,
+// every *Series[E] is a Column
+type Column interface {
+	Name() string
+	Len() int
+	DType() reflect.Kind
+	Any() *Series[any]
+	Str() *Series[string]
+	Float64() *Series[float64]
+}
+
+// Frame is a dataframe whose columns may have different element types,
+// e.g. *Series[int64], *Series[float64], *Series[string], *Series[bool] and *Series[time.Time],
+// so that values are stored unboxed.
+type Frame struct {
+	columns []Column
+	index   map[string]int
+}
+
+// Get returns a column by giving name or nil if not found
+func (f *Frame) Get(name string) Column {
+	if i, ok := f.index[name]; !ok {
+		return nil
+	} else {
+		return f.columns[i]
+	}
+}
+
+func (f *Frame) GetByIndex(i int) Column {
+	return f.columns[i]
+}
+
+// Columns return columns in frame
+func (f *Frame) Columns() []Column {
+	return f.columns
+}
+
+// Int64 returns the column of given name if it is stored as int64, otherwise nil
+func (f *Frame) Int64(name string) *Series[int64] {
+	s, _ := FrameColumn[int64](f, name)
+	return s
+}
+
+// Float64 returns the column of given name if it is stored as float64, otherwise nil
+func (f *Frame) Float64(name string) *Series[float64] {
+	s, _ := FrameColumn[float64](f, name)
+	return s
+}
+
+// Str returns the column of given name if it is stored as string, otherwise nil
+func (f *Frame) Str(name string) *Series[string] {
+	s, _ := FrameColumn[string](f, name)
+	return s
+}
+
+// Bool returns the column of given name if it is stored as bool, otherwise nil
+func (f *Frame) Bool(name string) *Series[bool] {
+	s, _ := FrameColumn[bool](f, name)
+	return s
+}
+
+// Time returns the column of given name if it is stored as time.Time, otherwise nil
+func (f *Frame) Time(name string) *Series[time.Time] {
+	s, _ := FrameColumn[time.Time](f, name)
+	return s
+}
+
+// NCols return number of columns
+func (f *Frame) NCols() int {
+	return len(f.columns)
+}
+
+// NRows return number of rows
+func (f *Frame) NRows() int {
+	if len(f.columns) == 0 {
+		return 0
+	}
+	return f.columns[0].Len()
+}
+
+// Shape return nrows and ncols
+func (f *Frame) Shape() (int, int) {
+	return f.NRows(), f.NCols()
+}
+
+func (f *Frame) Empty() bool {
+	return f.NCols() == 0 || f.NRows() == 0
+}
+
+// Names return column names
+func (f *Frame) Names() []string {
+	names := make([]string, 0, len(f.columns))
+	for _, column := range f.columns {
+		names = append(names, column.Name())
+	}
+	return names
+}
+
+// Index returns index of given name
+func (f *Frame) Index(name string) int {
+	return f.index[name]
+}
+
+func (f *Frame) DTypes() []reflect.Kind {
+	dtypes := make([]reflect.Kind, 0, len(f.columns))
+	for _, column := range f.columns {
+		dtypes = append(dtypes, column.DType())
+	}
+	return dtypes
+}
+
+// Schema returns names, dtypes of non-null values and nullability of columns
+func (f *Frame) Schema() Schema {
+	schema := make(Schema, 0, len(f.columns))
+	for _, column := range f.columns {
+		field := Field{Name: column.Name(), DType: column.DType()}
+		switch c := column.(type) {
+		case *Series[int64], *Series[uint64], *Series[string], *Series[bool], *Series[time.Time]:
+		case *Series[float64]:
+			for _, val := range c.elements {
+				if math.IsNaN(val) {
+					field.Nullable = true
+					break
+				}
+			}
+		default:
+			field.DType = reflect.Invalid
+			for _, val := range column.Any().elements {
+				if isNull(val) {
+					field.Nullable = true
+					continue
+				}
+				if kind := reflect.ValueOf(val).Kind(); field.DType == reflect.Invalid {
+					field.DType = kind
+				} else if field.DType != kind {
+					field.DType = reflect.Interface
+				}
+			}
+			if field.DType == reflect.Invalid {
+				field.DType = reflect.Interface
+			}
+		}
+		schema = append(schema, field)
+	}
+	return schema
+}
+
+// Any boxes all columns into a DataFrame[any]
+func (f *Frame) Any() *DataFrame[any] {
+	seriess := make([]*Series[any], 0, len(f.columns))
+	for _, column := range f.columns {
+		seriess = append(seriess, column.Any())
+	}
+	return NewDataFrame(seriess...)
+}
+
+func (f *Frame) ToCsvPath(filepath string, option WriteCSVOption) error {
+	out, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if err := f.ToCsv(out, option); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ToCsv is like DataFrame.ToCsv, values are read from typed columns without boxing the whole frame
+func (f *Frame) ToCsv(w io.Writer, option WriteCSVOption) error {
+	return writeCsv(w, frameExportColumns(f), option)
+}
+
+func (f *Frame) ToXlsxPath(filepath string, option WriteXlsxOption) error {
+	xw := NewXlsxWriter()
+	if err := xw.WriteFrame(f, option); err != nil {
+		return err
+	}
+	return xw.Save(filepath)
+}
+
+// ToXlsx is like DataFrame.ToXlsx, values are read from typed columns without boxing the whole frame
+func (f *Frame) ToXlsx(w io.Writer, option WriteXlsxOption) error {
+	xw := NewXlsxWriter()
+	if err := xw.WriteFrame(f, option); err != nil {
+		return err
+	}
+	_, err := xw.WriteTo(w)
+	return err
+}
+
+func (f *Frame) ToOdsPath(filepath string, option WriteOdsOption) error {
+	ow := NewOdsWriter()
+	if err := ow.WriteFrame(f, option); err != nil {
+		return err
+	}
+	return ow.Save(filepath)
+}
+
+// ToOds is like DataFrame.ToOds, values are read from typed columns without boxing the whole frame
+func (f *Frame) ToOds(w io.Writer, option WriteOdsOption) error {
+	ow := NewOdsWriter()
+	if err := ow.WriteFrame(f, option); err != nil {
+		return err
+	}
+	_, err := ow.WriteTo(w)
+	return err
+}
+
+func (f *Frame) ToParquetPath(filepath string, option ...WriteParquetOption) error {
+	out, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if err := f.ToParquet(out, option...); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ToParquet writes the frame like DataFrame.ToParquet, values are read from typed columns without boxing the whole frame
+func (f *Frame) ToParquet(w io.Writer, option ...WriteParquetOption) error {
+	return writeParquet("Frame.ToParquet", w, frameParquetColumns(f), f.NRows(), firstWriteParquetOption(option))
+}
+
+// FrameColumn returns the column of given name as *Series[E], ok is false if not found or stored as another type
+func FrameColumn[E any](f *Frame, name string) (*Series[E], bool) {
+	column := f.Get(name)
+	if column == nil {
+		return nil, false
+	}
+	s, ok := column.(*Series[E])
+	return s, ok
+}
+
+// NewFrame Create a frame by given columns
+func NewFrame(columns ...Column) *Frame {
+	f, err := TryNewFrame(columns...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// TryNewFrame is like NewFrame but returns ErrLengthMismatch or ErrDuplicateColumn instead of panic
+func TryNewFrame(columns ...Column) (*Frame, error) {
+	index := make(map[string]int, len(columns))
+	length := 0
+	for i, column := range columns {
+		name := column.Name()
+		if i == 0 {
+			length = column.Len()
+		} else if length != column.Len() {
+			return nil, newError("NewFrame", -1, name, ErrLengthMismatch, nil)
+		}
+		if _, ok := index[name]; ok {
+			return nil, newError("NewFrame", -1, name, ErrDuplicateColumn, nil)
+		}
+		index[name] = i
+	}
+
+	return &Frame{
+		columns: columns,
+		index:   index,
+	}, nil
+}
+
+// typedColumn stores values of series in the narrowest of int64, uint64, float64, string, bool and time.Time.
+// uint and uint64 values are stored as uint64 so that values beyond math.MaxInt64 are kept,
+// they are kept as any if mixed with signed integers. Integers with null values are stored as float64 with NaN,
+// strings, bools and times with null values and mixed values are kept as any.
+func typedColumn[E any](s *Series[E]) Column {
+	var (
+		hasInt, hasUint, hasFloat, hasString, hasBool, hasTime, hasOthers bool
+		nulls                                                             int
+	)
+	for _, val := range s.elements {
+		if isNull(val) {
+			nulls++
+			continue
+		}
+		ref := reflect.ValueOf(val)
+		if ref.Kind() == reflect.Pointer {
+			ref = ref.Elem()
+		}
+		switch ref.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			hasInt = true
+		case reflect.Uint, reflect.Uint64:
+			hasUint = true
+		case reflect.Float32, reflect.Float64:
+			hasFloat = true
+		case reflect.String:
+			hasString = true
+		case reflect.Bool:
+			hasBool = true
+		default:
+			if _, ok := ref.Interface().(time.Time); ok {
+				hasTime = true
+			} else {
+				hasOthers = true
+			}
+		}
+	}
+
+	kinds := 0
+	for _, has := range []bool{hasInt || hasUint || hasFloat, hasString, hasBool, hasTime, hasOthers} {
+		if has {
+			kinds++
+		}
+	}
+
+	switch {
+	case kinds != 1 || hasOthers:
+		return s.Any()
+	case hasUint && !hasInt && !hasFloat && nulls == 0:
+		elements := make([]uint64, 0, s.Len())
+		for _, val := range s.elements {
+			elements = append(elements, reflect.Indirect(reflect.ValueOf(val)).Uint())
+		}
+		return NewSeries(s.name, elements...)
+	case hasUint && hasInt && !hasFloat && nulls == 0:
+		return s.Any()
+	case hasInt && !hasFloat && nulls == 0:
+		elements := make([]int64, 0, s.Len())
+		for _, val := range s.elements {
+			ref := reflect.ValueOf(val)
+			if ref.Kind() == reflect.Pointer {
+				ref = ref.Elem()
+			}
+			if ref.CanInt() {
+				elements = append(elements, ref.Int())
+			} else {
+				elements = append(elements, int64(ref.Uint()))
+			}
+		}
+		return NewSeries(s.name, elements...)
+	case hasInt || hasUint || hasFloat:
+		elements := make([]float64, 0, s.Len())
+		for _, val := range s.elements {
+			if v, ok := nullableFloat64(val); ok {
+				elements = append(elements, v)
+			} else {
+				elements = append(elements, math.NaN())
+			}
+		}
+		return NewSeries(s.name, elements...)
+	case nulls > 0:
+		return s.Any()
+	case hasString:
+		return s.Str()
+	case hasBool:
+		elements := make([]bool, 0, s.Len())
+		for _, val := range s.elements {
+			ref := reflect.ValueOf(val)
+			if ref.Kind() == reflect.Pointer {
+				ref = ref.Elem()
+			}
+			elements = append(elements, ref.Bool())
+		}
+		return NewSeries(s.name, elements...)
+	case hasTime:
+		elements := make([]time.Time, 0, s.Len())
+		for _, val := range s.elements {
+			ref := reflect.ValueOf(val)
+			if ref.Kind() == reflect.Pointer {
+				ref = ref.Elem()
+			}
+			elements = append(elements, ref.Interface().(time.Time))
+		}
+		return NewSeries(s.name, elements...)
+	default:
+		return s.Any()
+	}
+}
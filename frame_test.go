@@ -0,0 +1,104 @@
+package pandat
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTyped(t *testing.T) {
+	now := time.Now()
+	df := NewDataFrame(
+		NewSeries[any]("int", int64(1), 2, int8(3)),
+		NewSeries[any]("nullable", 1, nil, 3),
+		NewSeries[any]("float", 1, 2.5, math.NaN()),
+		NewSeries[any]("str", "a", "b", "c"),
+		NewSeries[any]("bool", true, false, true),
+		NewSeries[any]("time", now, now, now),
+		NewSeries[any]("mixed", "a", 1, true),
+	)
+
+	frame := df.Typed()
+	expected := []reflect.Kind{
+		reflect.Int64, reflect.Float64, reflect.Float64, reflect.String, reflect.Bool, reflect.Struct, reflect.Interface,
+	}
+	if dtypes := frame.DTypes(); !reflect.DeepEqual(dtypes, expected) {
+		t.Fatalf("expected %v, got %v", expected, dtypes)
+	}
+
+	if s := frame.Int64("int"); s == nil || s.Get(2) != 3 {
+		t.Fatalf("unexpected int column: %v", s)
+	}
+	if s := frame.Float64("nullable"); s == nil || !math.IsNaN(s.Get(1)) {
+		t.Fatalf("unexpected nullable column: %v", s)
+	}
+	if s := frame.Time("time"); s == nil || !s.Get(0).Equal(now) {
+		t.Fatalf("unexpected time column: %v", s)
+	}
+	if s := frame.Int64("str"); s != nil {
+		t.Fatalf("string column should not be accessed as int64")
+	}
+	if _, ok := FrameColumn[any](frame, "mixed"); !ok {
+		t.Fatalf("mixed column should be kept as any")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := frame.ToCsv(buf, WriteCSVOption{Comma: ','}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTypedUint(t *testing.T) {
+	frame := NewDataFrame(
+		NewSeries[any]("uint", uint64(math.MaxUint64), uint(1)),
+		NewSeries[any]("mixed", uint64(math.MaxUint64), -1),
+	).Typed()
+	if s, ok := FrameColumn[uint64](frame, "uint"); !ok || s.Get(0) != math.MaxUint64 {
+		t.Fatalf("unexpected uint column: %v", frame.Get("uint"))
+	}
+	if _, ok := FrameColumn[any](frame, "mixed"); !ok {
+		t.Fatalf("unsigned and signed integers should be kept as any")
+	}
+}
+
+func TestFrameExport(t *testing.T) {
+	frame := NewFrame(
+		NewSeries("id", int64(1), int64(2)),
+		NewSeries("big", uint64(math.MaxUint64), uint64(0)),
+		NewSeries("amount", 1.5, math.NaN()),
+		NewSeries("name", "a", "b"),
+	)
+
+	buf := new(bytes.Buffer)
+	if err := frame.ToCsv(buf, WriteCSVOption{}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "id,big,amount,name\n1,18446744073709551615,1.5,a\n2,0,NaN,b\n"; buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := frame.ToXlsx(buf, WriteXlsxOption{Index: true, NumberFormats: map[string]string{"amount": "0.00"}}); err != nil {
+		t.Fatal(err)
+	}
+	df, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"", "id", "big", "amount", "name"}) || df.Val(1, "name") != "b" || !isNull(df.Val(1, "amount")) {
+		t.Fatalf("unexpected xlsx: %v", df)
+	}
+
+	buf.Reset()
+	if err := frame.ToOds(buf, WriteOdsOption{}); err != nil {
+		t.Fatal(err)
+	}
+	if df, err = ReadOds(bytes.NewReader(buf.Bytes()), ReadXlsxOption{}); err != nil {
+		t.Fatal(err)
+	}
+	if df.Val(0, "id") != int64(1) || df.Val(1, "name") != "b" {
+		t.Fatalf("unexpected ods: %v", df)
+	}
+}
@@ -0,0 +1,77 @@
+package pandat
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HeaderOption normalizes column names read from files, names are processed in the order of the fields
+type HeaderOption struct {
+	// TrimSpace removes leading and trailing white spaces of names
+	TrimSpace bool
+	// NameBlanks names blank columns as "Unnamed: i", i is the index of the column
+	NameBlanks bool
+	// Sanitize replaces characters which are not letters, digits or underscores with underscores,
+	// and prefixes an underscore to names which start with a digit, so that names are valid identifiers
+	Sanitize bool
+	// MangleDuplicates renames duplicate names as "name.1", "name.2" and so on
+	MangleDuplicates bool
+}
+
+// normalize returns normalized names, given names are not modified
+func (o HeaderOption) normalize(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for i, name := range names {
+		if o.TrimSpace {
+			name = strings.TrimSpace(name)
+		}
+		if o.NameBlanks && strings.TrimSpace(name) == "" {
+			name = "Unnamed: " + strconv.Itoa(i)
+		}
+		if o.Sanitize {
+			name = sanitizeName(name)
+		}
+		normalized = append(normalized, name)
+	}
+
+	if o.MangleDuplicates {
+		used := newSet(normalized...)
+		counts := make(map[string]int, len(normalized))
+		for i, name := range normalized {
+			count, seen := counts[name]
+			if !seen {
+				counts[name] = 1
+				continue
+			}
+			// skip suffixes which are taken by other columns, e.g. "a", "a.1", "a"
+			candidate := name + "." + strconv.Itoa(count)
+			for used.Contains(candidate) {
+				count++
+				candidate = name + "." + strconv.Itoa(count)
+			}
+			counts[name] = count + 1
+			used.Add(candidate)
+			normalized[i] = candidate
+		}
+	}
+	return normalized
+}
+
+func sanitizeName(name string) string {
+	buf := new(strings.Builder)
+	for i, r := range name {
+		if i == 0 && unicode.IsDigit(r) {
+			buf.WriteRune('_')
+		}
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			buf.WriteRune(r)
+		} else {
+			buf.WriteRune('_')
+		}
+	}
+	if buf.Len() == 0 {
+		return "_"
+	}
+	return buf.String()
+}
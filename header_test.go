@@ -0,0 +1,44 @@
+package pandat
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderOption(t *testing.T) {
+	option := HeaderOption{TrimSpace: true, NameBlanks: true, MangleDuplicates: true}
+	names := option.normalize([]string{"amount", " amount ", "", "amount.1", "amount"})
+	expected := []string{"amount", "amount.2", "Unnamed: 2", "amount.1", "amount.3"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+
+	option = HeaderOption{Sanitize: true}
+	names = option.normalize([]string{"unit price ($)", "2022", "名称"})
+	expected = []string{"unit_price____", "_2022", "名称"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestReadDuplicateHeader(t *testing.T) {
+	input := "amount,amount,\n1,2,3\n"
+	if _, err := ReadCsv(strings.NewReader(input), ReadCsvOption{}); !errors.Is(err, ErrDuplicateColumn) {
+		t.Fatalf("expected ErrDuplicateColumn, got %v", err)
+	}
+
+	df, err := ReadCsv(strings.NewReader(input), ReadCsvOption{Header: HeaderOption{NameBlanks: true, MangleDuplicates: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"amount", "amount.1", "Unnamed: 2"}) {
+		t.Fatalf("unexpected names: %v", df.Names())
+	}
+
+	df = ReadSlice([][]string{{" a", "1"}, {"a ", "2"}}, true, ReadSliceOption{Header: HeaderOption{TrimSpace: true, MangleDuplicates: true}})
+	if !reflect.DeepEqual(df.Names(), []string{"a", "a.1"}) {
+		t.Fatalf("unexpected names: %v", df.Names())
+	}
+}
@@ -0,0 +1,485 @@
+package pandat
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TypeInferenceOption is the vocabulary of bool and null values used by readers to infer and convert values
+type TypeInferenceOption struct {
+	// TrueValues are read as true, DefaultTrueValues is used if nil, e.g. "Y", "yes", "1", "是"
+	TrueValues []string
+	// FalseValues are read as false, DefaultFalseValues is used if nil, e.g. "N", "no", "0", "否"
+	FalseValues []string
+	// NullValues are read as NaN for float64 and mixed columns, nil for other columns,
+	// DefaultNullValues is used if nil
+	NullValues []string
+	// SampleRows is the number of leading rows examined to infer dtypes, 0 means all rows.
+	// Columns whose later values do not fit the inferred dtype are inferred again from all values.
+	SampleRows int
+	// ParseLeadingZeros reads numbers with leading zeros like "00123" as numbers, they are strings by default
+	ParseLeadingZeros bool
+}
+
+var (
+	defaultTrueValues  = []string{"true", "True"}
+	defaultFalseValues = []string{"false", "False"}
+	defaultNullValues  = []string{"", "NaN", "None", "null"}
+)
+
+// DefaultTrueValues returns a copy of the values read as true by default, e.g. to extend TrueValues
+func DefaultTrueValues() []string {
+	return append([]string(nil), defaultTrueValues...)
+}
+
+// DefaultFalseValues returns a copy of the values read as false by default
+func DefaultFalseValues() []string {
+	return append([]string(nil), defaultFalseValues...)
+}
+
+// DefaultNullValues returns a copy of the values read as null by default
+func DefaultNullValues() []string {
+	return append([]string(nil), defaultNullValues...)
+}
+
+// Field describes a column of a Schema
+type Field struct {
+	Name  string
+	DType reflect.Kind
+	// Nullable is true if the column has null values
+	Nullable bool
+}
+
+// Schema is the inferred names and dtypes of columns
+type Schema []Field
+
+// DTypes returns dtypes of fields
+func (s Schema) DTypes() []reflect.Kind {
+	dtypes := make([]reflect.Kind, 0, len(s))
+	for _, field := range s {
+		dtypes = append(dtypes, field.DType)
+	}
+	return dtypes
+}
+
+func (s Schema) String() string {
+	var b strings.Builder
+	for i, field := range s {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(field.Name + " " + field.DType.String())
+		if field.Nullable {
+			b.WriteString(" nullable")
+		}
+	}
+	return b.String()
+}
+
+// valueParser infers types of columns and parses strings into values
+type valueParser struct {
+	number            NumberOption
+	trues             map[string]struct{}
+	falses            map[string]struct{}
+	nulls             map[string]struct{}
+	sampleRows        int
+	parseLeadingZeros bool
+}
+
+// newValueParser returns a parser of given options, extraNulls are read as null besides option.NullValues
+func newValueParser(number NumberOption, option TypeInferenceOption, extraNulls ...string) valueParser {
+	trues, falses, nulls := option.TrueValues, option.FalseValues, option.NullValues
+	if trues == nil {
+		trues = defaultTrueValues
+	}
+	if falses == nil {
+		falses = defaultFalseValues
+	}
+	if nulls == nil {
+		nulls = defaultNullValues
+	}
+	return valueParser{
+		number: number,
+		trues:  stringSet(trues),
+		falses: stringSet(falses),
+		nulls:  stringSet(append(append([]string(nil), nulls...), extraNulls...)),
+
+		sampleRows:        option.SampleRows,
+		parseLeadingZeros: option.ParseLeadingZeros,
+	}
+}
+
+func stringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, val := range values {
+		set[val] = struct{}{}
+	}
+	return set
+}
+
+func (p valueParser) isNull(val string) bool {
+	_, ok := p.nulls[val]
+	return ok
+}
+
+// parseBool returns the bool value of val, ok is false if val is neither a true value nor a false value
+func (p valueParser) parseBool(val string) (bool, bool) {
+	if _, ok := p.trues[val]; ok {
+		return true, true
+	}
+	if _, ok := p.falses[val]; ok {
+		return false, true
+	}
+	return false, false
+}
+
+func (p valueParser) hasNull(arr []string) bool {
+	for _, val := range arr {
+		if p.isNull(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadingZero returns true if val is an integer part with leading zeros like "00123" which is kept as string
+func (p valueParser) leadingZero(val string) bool {
+	if p.parseLeadingZeros {
+		return false
+	}
+	val = strings.TrimSpace(val)
+	if len(val) > 0 && (val[0] == '-' || val[0] == '+') {
+		val = val[1:]
+	}
+	return len(val) > 1 && val[0] == '0' && val[1] >= '0' && val[1] <= '9'
+}
+
+// determineType infers the dtype of a column from the first sampleRows values,
+// or from all values if the samples are all null
+func (p valueParser) determineType(arr []string) reflect.Kind {
+	if p.sampleRows > 0 && len(arr) > p.sampleRows {
+		if dtype := p.inferType(arr[:p.sampleRows]); dtype != reflect.Interface {
+			return dtype
+		}
+	}
+	return p.inferType(arr)
+}
+
+// inferType infers the dtype of values, promoting int64 → uint64 → float64 → string.
+// Columns of only null values are reflect.Interface.
+func (p valueParser) inferType(arr []string) reflect.Kind {
+	var (
+		hasBool, hasInt, hasNegative, hasUint, hasFloat, hasNaN bool
+		// numeric bool values like "1" and "0" are bools unless there are other numbers
+		hasNumericBool, hasNumericBoolFloat bool
+	)
+	for _, val := range arr {
+		if p.isNull(val) {
+			// a NaN column is not an int column
+			if _, err := p.number.parseFloat(val); err == nil {
+				hasNaN = true
+			}
+			continue
+		}
+		if _, ok := p.parseBool(val); ok {
+			if _, err := p.number.parseInt(val); err == nil {
+				hasNumericBool = true
+			} else if _, err := p.number.parseFloat(val); err == nil {
+				hasNumericBool, hasNumericBoolFloat = true, true
+			} else {
+				hasBool = true
+			}
+			continue
+		}
+		if p.leadingZero(val) {
+			return reflect.String
+		}
+		v, err := p.number.parseInt(val)
+		if err == nil {
+			hasInt = true
+			hasNegative = hasNegative || v < 0
+			continue
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			if _, err := p.number.parseUint(val); err == nil {
+				hasUint = true
+				continue
+			}
+			// integers overflowing uint64 are kept as strings so that no digit is lost
+			return reflect.String
+		}
+		if _, err := p.number.parseFloat(val); err == nil {
+			hasFloat = true
+			continue
+		}
+
+		return reflect.String // fast break on string
+	}
+
+	switch {
+	case !hasInt && !hasUint && !hasFloat:
+		if hasBool || hasNumericBool {
+			return reflect.Bool
+		} else if hasNaN {
+			return reflect.Float64
+		}
+		// only null values
+		return reflect.Interface
+	case hasBool:
+		// mixed bool and numbers
+		return reflect.String
+	case hasFloat || hasNumericBoolFloat || hasNaN || (hasUint && hasNegative):
+		return reflect.Float64
+	case hasUint:
+		return reflect.Uint64
+	default:
+		return reflect.Int64
+	}
+}
+
+func (p valueParser) asType(name string, arr []string, dtype reflect.Kind) ([]any, error) {
+	switch dtype {
+	case reflect.Float64:
+		return p.asFloat64(name, arr)
+	case reflect.Int64:
+		return p.asInt64(name, arr)
+	case reflect.Uint64:
+		return p.asUint64(name, arr)
+	case reflect.Bool:
+		return p.asBool(name, arr)
+	case reflect.String:
+		return p.asString(arr), nil
+	case reflect.Interface:
+		return p.asInterface(arr), nil
+	default:
+		return nil, newError("asType", -1, name, ErrConversion, errors.New("unsupported type "+dtype.String()))
+	}
+}
+
+func (p valueParser) asFloat64(name string, arr []string) ([]any, error) {
+	values := make([]any, 0, len(arr))
+
+	for i, val := range arr {
+		if p.isNull(val) {
+			values = append(values, math.NaN())
+		} else if v, err := p.parseFloat(val); err == nil {
+			values = append(values, v)
+		} else {
+			return nil, newError("asFloat64", i, name, ErrConversion, err)
+		}
+	}
+
+	return values, nil
+}
+
+// parseFloat is like NumberOption.parseFloat but also accepts "Inf" and "-Inf",
+// numbers with leading zeros are rejected like parseInt
+func (p valueParser) parseFloat(val string) (float64, error) {
+	switch val {
+	case "Inf", "inf":
+		return math.Inf(1), nil
+	case "-Inf", "-inf":
+		return math.Inf(-1), nil
+	}
+	if p.leadingZero(val) {
+		return 0, errLeadingZero(val)
+	}
+	return p.number.parseFloat(val)
+}
+
+// parseInt is like NumberOption.parseInt but rejects numbers with leading zeros which are inferred as strings,
+// so that a column sampled as numbers is inferred again if later values have leading zeros
+func (p valueParser) parseInt(val string) (int64, error) {
+	if p.leadingZero(val) {
+		return 0, errLeadingZero(val)
+	}
+	return p.number.parseInt(val)
+}
+
+// parseUint is like parseInt but for uint64
+func (p valueParser) parseUint(val string) (uint64, error) {
+	if p.leadingZero(val) {
+		return 0, errLeadingZero(val)
+	}
+	return p.number.parseUint(val)
+}
+
+func errLeadingZero(val string) error {
+	return errors.New("number with leading zeros: " + val)
+}
+
+func (p valueParser) asInt64(name string, arr []string) ([]any, error) {
+	values := make([]any, 0, len(arr))
+	for i, val := range arr {
+		if p.isNull(val) {
+			values = append(values, nil)
+		} else if v, err := p.parseInt(val); err == nil {
+			values = append(values, v)
+		} else {
+			return nil, newError("asInt64", i, name, ErrConversion, err)
+		}
+	}
+
+	return values, nil
+}
+
+func (p valueParser) asUint64(name string, arr []string) ([]any, error) {
+	values := make([]any, 0, len(arr))
+	for i, val := range arr {
+		if p.isNull(val) {
+			values = append(values, nil)
+		} else if v, err := p.parseUint(val); err == nil {
+			values = append(values, v)
+		} else {
+			return nil, newError("asUint64", i, name, ErrConversion, err)
+		}
+	}
+
+	return values, nil
+}
+
+func (p valueParser) asBool(name string, arr []string) ([]any, error) {
+	values := make([]any, 0, len(arr))
+	for i, val := range arr {
+		if p.isNull(val) {
+			values = append(values, nil)
+		} else if v, ok := p.parseBool(val); ok {
+			values = append(values, v)
+		} else {
+			return nil, newError("asBool", i, name, ErrConversion, errors.New("not a bool value: "+val))
+		}
+	}
+
+	return values, nil
+}
+
+func (p valueParser) asString(arr []string) []any {
+	values := make([]any, 0, len(arr))
+	for _, val := range arr {
+		if p.isNull(val) {
+			values = append(values, nil)
+		} else {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+
+func (p valueParser) asInterface(arr []string) []any {
+	values := make([]any, 0, len(arr))
+	for _, val := range arr {
+		if p.isNull(val) {
+			values = append(values, math.NaN())
+			continue
+		}
+		if v, ok := p.parseBool(val); ok {
+			values = append(values, v)
+			continue
+		}
+		if v, err := p.number.parseInt(val); err == nil {
+			values = append(values, v)
+			continue
+		}
+		if v, err := p.number.parseFloat(val); err == nil {
+			values = append(values, v)
+			continue
+		}
+
+		// default is string
+		values = append(values, val)
+	}
+	return values
+}
+
+// asColumn converts values into a column of dtype without boxing.
+// Integers with null values are stored as float64 with NaN,
+// strings and bools with null values are kept as any, see typedColumn.
+func (p valueParser) asColumn(name string, arr []string, dtype reflect.Kind) (Column, error) {
+	switch dtype {
+	case reflect.Float64:
+		values, _, err := typedValues(p, "asFloat64", name, arr, p.parseFloat)
+		if err != nil {
+			return nil, err
+		}
+		return NewSeries(name, values...), nil
+	case reflect.Int64:
+		values, nulls, err := typedValues(p, "asInt64", name, arr, p.parseInt)
+		if err != nil {
+			return nil, err
+		}
+		if nulls {
+			return NewSeries(name, nullableFloat64s(p, arr, values)...), nil
+		}
+		return NewSeries(name, values...), nil
+	case reflect.Uint64:
+		values, nulls, err := typedValues(p, "asUint64", name, arr, p.parseUint)
+		if err != nil {
+			return nil, err
+		}
+		if nulls {
+			return NewSeries(name, nullableFloat64s(p, arr, values)...), nil
+		}
+		return NewSeries(name, values...), nil
+	case reflect.Bool:
+		values, nulls, err := typedValues(p, "asBool", name, arr, func(val string) (bool, error) {
+			if v, ok := p.parseBool(val); ok {
+				return v, nil
+			}
+			return false, errors.New("not a bool value: " + val)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if nulls {
+			elements, _ := p.asBool(name, arr)
+			return NewSeries(name, elements...), nil
+		}
+		return NewSeries(name, values...), nil
+	case reflect.String:
+		values, nulls, _ := typedValues(p, "asString", name, arr, func(val string) (string, error) { return val, nil })
+		if nulls {
+			return NewSeries(name, p.asString(arr)...), nil
+		}
+		return NewSeries(name, values...), nil
+	default:
+		elements, err := p.asType(name, arr, dtype)
+		if err != nil {
+			return nil, err
+		}
+		return NewSeries(name, elements...), nil
+	}
+}
+
+// typedValues parses non-null values, null values are zero values or NaN for float64, nulls is true if there are null values
+func typedValues[E any](p valueParser, op, name string, arr []string, parse func(string) (E, error)) (values []E, nulls bool, err error) {
+	values = make([]E, len(arr))
+	for i, val := range arr {
+		if p.isNull(val) {
+			nulls = true
+			if nan, ok := any(math.NaN()).(E); ok {
+				values[i] = nan
+			}
+			continue
+		}
+		if values[i], err = parse(val); err != nil {
+			return nil, false, newError(op, i, name, ErrConversion, err)
+		}
+	}
+	return values, nulls, nil
+}
+
+// nullableFloat64s converts integers into float64 with NaN for null values
+func nullableFloat64s[E int64 | uint64](p valueParser, arr []string, values []E) []float64 {
+	floats := make([]float64, len(values))
+	for i, val := range values {
+		if p.isNull(arr[i]) {
+			floats[i] = math.NaN()
+		} else {
+			floats[i] = float64(val)
+		}
+	}
+	return floats
+}
@@ -0,0 +1,176 @@
+package pandat
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypeInferenceOption(t *testing.T) {
+	option := TypeInferenceOption{
+		TrueValues:  []string{"Y", "yes", "1", "是"},
+		FalseValues: []string{"N", "no", "0", "否"},
+		NullValues:  []string{"", "-", "N/A"},
+	}
+	parser := newValueParser(NumberOption{}, option)
+	cases := []struct {
+		values   []string
+		expected reflect.Kind
+	}{
+		{[]string{"Y", "N", "-"}, reflect.Bool},
+		{[]string{"是", "否", "yes"}, reflect.Bool},
+		{[]string{"1", "0", "1"}, reflect.Bool},
+		{[]string{"1", "0", "2"}, reflect.Int64},
+		{[]string{"1", "0", "2.5"}, reflect.Float64},
+		{[]string{"Y", "0", "2"}, reflect.String},
+		{[]string{"true", "false"}, reflect.String},
+		{[]string{"N/A", "-"}, reflect.Interface},
+	}
+	for _, c := range cases {
+		if dtype := parser.determineType(c.values); dtype != c.expected {
+			t.Fatalf("%v: expected %v, got %v", c.values, c.expected, dtype)
+		}
+	}
+
+	df, err := ReadCsv(strings.NewReader("a,b,c,d\nY,2,x,1.5\n否,-,N/A,N/A\n"), ReadCsvOption{Inference: option})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := df.Get("a").Slice(); !reflect.DeepEqual(a, []any{true, false}) {
+		t.Fatalf("unexpected a: %v", a)
+	}
+	if b := df.Get("b").Slice(); !reflect.DeepEqual(b, []any{int64(2), nil}) {
+		t.Fatalf("unexpected b: %v", b)
+	}
+	if c := df.Val(1, "c"); !isNull(c) {
+		t.Fatalf("expected null c, got %v", c)
+	}
+	if d, ok := df.Val(1, "d").(float64); !ok || !math.IsNaN(d) {
+		t.Fatalf("expected NaN d, got %v", df.Val(1, "d"))
+	}
+
+	// "NaN" is a null value by default and makes the column float64
+	df = ReadSlice([][]string{{"x", "1", "NaN"}}, true)
+	if df.Get("x").DType() != reflect.Float64 {
+		t.Fatalf("expected float64, got %v", df.Get("x").DType())
+	}
+
+	// extending the defaults does not change them
+	nulls := append(DefaultNullValues()[:1], "-")
+	if !reflect.DeepEqual(DefaultNullValues(), []string{"", "NaN", "None", "null"}) || len(nulls) != 2 {
+		t.Fatalf("default null values are modified: %v", DefaultNullValues())
+	}
+}
+
+func TestInferType(t *testing.T) {
+	parser := newValueParser(NumberOption{}, TypeInferenceOption{})
+	cases := []struct {
+		values   []string
+		expected reflect.Kind
+	}{
+		{[]string{"1", "2", "x"}, reflect.String},
+		{[]string{"1", "2.5", ""}, reflect.Float64},
+		{[]string{"1", "18446744073709551615"}, reflect.Uint64},
+		{[]string{"-1", "18446744073709551615"}, reflect.Float64},
+		{[]string{"1", "99999999999999999999999"}, reflect.String},
+		{[]string{"00123", "45678"}, reflect.String},
+		{[]string{"0", "0.5", "-0.25"}, reflect.Float64},
+		{[]string{"true", "1"}, reflect.String},
+		{[]string{"", "null"}, reflect.Interface},
+	}
+	for _, c := range cases {
+		if dtype := parser.inferType(c.values); dtype != c.expected {
+			t.Fatalf("%v: expected %v, got %v", c.values, c.expected, dtype)
+		}
+	}
+	if dtype := newValueParser(NumberOption{}, TypeInferenceOption{ParseLeadingZeros: true}).inferType([]string{"00123"}); dtype != reflect.Int64 {
+		t.Fatalf("expected int64, got %v", dtype)
+	}
+
+	// values after the samples promote the column
+	option := ReadSliceOption{Inference: TypeInferenceOption{SampleRows: 2}}
+	schema := InferSchema([][]string{{"a", "1", "2", "x"}}, true, option)
+	if schema[0].DType != reflect.Int64 {
+		t.Fatalf("expected int64 inferred from samples, got %v", schema[0].DType)
+	}
+	df := ReadSlice([][]string{{"a", "1", "2", "x"}, {"b", "1", "2", "3.5"}}, true, option)
+	if a := df.Get("a").Slice(); !reflect.DeepEqual(a, []any{"1", "2", "x"}) {
+		t.Fatalf("unexpected a: %v", a)
+	}
+	if b := df.Get("b").Slice(); !reflect.DeepEqual(b, []any{1.0, 2.0, 3.5}) {
+		t.Fatalf("unexpected b: %v", b)
+	}
+
+	// null samples do not determine the dtype
+	nulls := [][]string{{"c", "", "", "", "4", "5"}}
+	if schema := InferSchema(nulls, true, option); schema[0].DType != reflect.Int64 || !schema[0].Nullable {
+		t.Fatalf("expected nullable int64, got %v", schema)
+	}
+	if c := ReadSliceTyped(nulls, true, option).Float64("c"); c == nil || c.Get(3) != 4 {
+		t.Fatalf("expected float64 c, got %v", c)
+	}
+
+	// leading zeros after the samples are kept as strings
+	codes := [][]string{{"code", "10", "20", "00123"}, {"rate", "1.5", "2", "00.5"}}
+	df = ReadSlice(codes, true, option)
+	if code := df.Get("code").Slice(); !reflect.DeepEqual(code, []any{"10", "20", "00123"}) {
+		t.Fatalf("unexpected code: %v", code)
+	}
+	if rate := df.Get("rate").Slice(); !reflect.DeepEqual(rate, []any{"1.5", "2", "00.5"}) {
+		t.Fatalf("unexpected rate: %v", rate)
+	}
+	if code := ReadSliceTyped(codes, true, option).Str("code"); code == nil || code.Get(2) != "00123" {
+		t.Fatalf("expected string code, got %v", code)
+	}
+	option.Inference.ParseLeadingZeros = true
+	if code := ReadSlice(codes, true, option).Get("code").Slice(); !reflect.DeepEqual(code, []any{int64(10), int64(20), int64(123)}) {
+		t.Fatalf("unexpected code: %v", code)
+	}
+}
+
+func TestReadTyped(t *testing.T) {
+	f := ReadSliceTyped([][]string{
+		{"id", "1", "2", "3"},
+		{"zip", "00123", "10001", "02134"},
+		{"score", "1", "", "3"},
+		{"big", "1", "18446744073709551615", "2"},
+		{"ok", "true", "false", "true"},
+		{"note", "x", "", "z"},
+	}, true)
+	if id := f.Int64("id"); id == nil || !reflect.DeepEqual(id.Slice(), []int64{1, 2, 3}) {
+		t.Fatalf("unexpected id: %v", f.Get("id"))
+	}
+	if zip := f.Str("zip"); zip == nil || zip.Get(0) != "00123" {
+		t.Fatalf("unexpected zip: %v", f.Get("zip"))
+	}
+	if score := f.Float64("score"); score == nil || !math.IsNaN(score.Get(1)) {
+		t.Fatalf("unexpected score: %v", f.Get("score"))
+	}
+	if big, ok := FrameColumn[uint64](f, "big"); !ok || big.Get(1) != math.MaxUint64 {
+		t.Fatalf("unexpected big: %v", f.Get("big"))
+	}
+	if f.Bool("ok") == nil {
+		t.Fatalf("unexpected ok: %v", f.Get("ok"))
+	}
+
+	expected := Schema{
+		{"id", reflect.Int64, false},
+		{"zip", reflect.String, false},
+		{"score", reflect.Float64, true},
+		{"big", reflect.Uint64, false},
+		{"ok", reflect.Bool, false},
+		{"note", reflect.String, true},
+	}
+	if schema := f.Schema(); !reflect.DeepEqual(schema, expected) {
+		t.Fatalf("unexpected schema: %v", schema)
+	}
+
+	f, err := ReadCsvTyped(strings.NewReader("a,b\n1,x\n2,y\n"), ReadCsvOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema := f.Schema().String(); schema != "a int64, b string" {
+		t.Fatalf("unexpected schema: %s", schema)
+	}
+}
@@ -0,0 +1,173 @@
+package pandat
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// NumberOption describes how numbers are formatted in files, e.g. "1,234.50", "1.234,50", "12%", "¥99.00" or "(100)".
+// The zero value only accepts numbers in Go syntax.
+type NumberOption struct {
+	// Thousands is the thousands separator between groups of 3 digits, e.g. ',' or '.', 0 means none.
+	// It must differ from the decimal mark, and "1,2,3" is not a number.
+	Thousands rune
+	// Decimal is the decimal mark, e.g. ',', 0 means '.'
+	Decimal rune
+	// Percent parses "12%" as 0.12
+	Percent bool
+	// Currencies are currency symbols which are stripped before or after numbers, e.g. "$", "¥", "€", "USD"
+	Currencies []string
+	// AccountingNegative parses "(100)" as -100
+	AccountingNegative bool
+}
+
+var errNotNumber = errors.New("not a number")
+
+// check returns an error if the thousands separator is the decimal mark, which makes numbers ambiguous
+func (o NumberOption) check() error {
+	if o.Thousands != 0 && o.Thousands == o.decimal() {
+		return errors.New("pandat: thousands separator " + strconv.QuoteRune(o.Thousands) + " is the decimal mark")
+	}
+	return nil
+}
+
+// decimal returns the decimal mark, '.' by default
+func (o NumberOption) decimal() rune {
+	if o.Decimal == 0 {
+		return '.'
+	}
+	return o.Decimal
+}
+
+// plain returns true if numbers are in Go syntax
+func (o NumberOption) plain() bool {
+	return o.Thousands == 0 && (o.Decimal == 0 || o.Decimal == '.') && !o.Percent && len(o.Currencies) == 0 && !o.AccountingNegative
+}
+
+func (o NumberOption) parseInt(s string) (int64, error) {
+	if o.plain() {
+		return strconv.ParseInt(s, 10, 64)
+	}
+	cleaned, percent, ok := o.clean(s)
+	if !ok || percent {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: errNotNumber}
+	}
+	return strconv.ParseInt(cleaned, 10, 64)
+}
+
+func (o NumberOption) parseUint(s string) (uint64, error) {
+	if o.plain() {
+		return strconv.ParseUint(s, 10, 64)
+	}
+	cleaned, percent, ok := o.clean(s)
+	if !ok || percent {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: errNotNumber}
+	}
+	return strconv.ParseUint(cleaned, 10, 64)
+}
+
+func (o NumberOption) parseFloat(s string) (float64, error) {
+	if o.plain() {
+		return strconv.ParseFloat(s, 64)
+	}
+	cleaned, percent, ok := o.clean(s)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: s, Err: errNotNumber}
+	}
+	v, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		v /= 100
+	}
+	return v, nil
+}
+
+// clean converts s into Go syntax, percent is true if s ends with a percent sign
+func (o NumberOption) clean(s string) (cleaned string, percent bool, ok bool) {
+	s = strings.TrimSpace(s)
+
+	negative := false
+	if o.AccountingNegative && len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		negative = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	// sign may be placed before or after currency symbol, e.g. -$100 or $-100
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], strings.TrimSpace(s[1:])
+	}
+	for _, currency := range o.Currencies {
+		if strings.HasPrefix(s, currency) {
+			s = strings.TrimSpace(strings.TrimPrefix(s, currency))
+			break
+		} else if strings.HasSuffix(s, currency) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, currency))
+			break
+		}
+	}
+	if sign == "" && (strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+")) {
+		sign, s = s[:1], s[1:]
+	}
+
+	if o.Percent && strings.HasSuffix(s, "%") {
+		percent = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	}
+
+	if o.Thousands != 0 {
+		if o.Thousands == o.decimal() {
+			return "", false, false
+		}
+		if s, ok = o.ungroup(s); !ok {
+			return "", false, false
+		}
+	}
+	if o.Decimal != 0 && o.Decimal != '.' {
+		if strings.ContainsRune(s, '.') {
+			return "", false, false
+		}
+		s = strings.ReplaceAll(s, string(o.Decimal), ".")
+	}
+
+	if negative {
+		if sign != "" {
+			return "", false, false
+		}
+		sign = "-"
+	}
+	if s == "" {
+		return "", false, false
+	}
+	return sign + s, percent, true
+}
+
+// ungroup removes thousands separators of s, which are only accepted between groups of 3 digits
+// in the integer part, e.g. "1,234,567.5" but not "1,2,3" or "1.5,000"
+func (o NumberOption) ungroup(s string) (string, bool) {
+	integer, fraction := s, ""
+	if i := strings.IndexRune(s, o.decimal()); i >= 0 {
+		integer, fraction = s[:i], s[i:]
+	}
+	if strings.ContainsRune(fraction, o.Thousands) {
+		return "", false
+	}
+	groups := strings.Split(integer, string(o.Thousands))
+	if len(groups) == 1 {
+		return s, true
+	}
+	for i, group := range groups {
+		if (i == 0 && (len(group) == 0 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+			return "", false
+		}
+		for _, c := range group {
+			if c < '0' || c > '9' {
+				return "", false
+			}
+		}
+	}
+	return strings.Join(groups, "") + fraction, true
+}
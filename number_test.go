@@ -0,0 +1,76 @@
+package pandat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNumberOption(t *testing.T) {
+	option := NumberOption{Thousands: ',', Percent: true, Currencies: []string{"¥", "$"}, AccountingNegative: true}
+	cases := map[string]float64{
+		"1,234.50": 1234.5,
+		"12%":      0.12,
+		"¥99.00":   99,
+		"-$5":      -5,
+		"$-5":      -5,
+		"(100)":    -100,
+		"(¥1,000)": -1000,
+	}
+	for s, expected := range cases {
+		if v, err := option.parseFloat(s); err != nil || v != expected {
+			t.Fatalf("%s: expected %v, got %v %v", s, expected, v, err)
+		}
+	}
+	if v, err := option.parseInt("1,234"); err != nil || v != 1234 {
+		t.Fatalf("expected 1234, got %v %v", v, err)
+	}
+	if _, err := option.parseInt("12%"); err == nil {
+		t.Fatalf("percent should not be parsed as int")
+	}
+
+	european := NumberOption{Thousands: '.', Decimal: ','}
+	if v, err := european.parseFloat("1.234,50"); err != nil || v != 1234.5 {
+		t.Fatalf("expected 1234.5, got %v %v", v, err)
+	}
+	if _, err := (NumberOption{Decimal: ','}).parseFloat("1.5"); err == nil {
+		t.Fatalf("dot should not be accepted when decimal mark is comma")
+	}
+
+	for _, s := range []string{"1,2,3", "1234,567", ",123", "1,23", "1.5,000", "12,3456"} {
+		if v, err := option.parseFloat(s); err == nil {
+			t.Fatalf("%s: expected invalid thousands groups, got %v", s, v)
+		}
+	}
+	if v, err := option.parseInt("1,234,567"); err != nil || v != 1234567 {
+		t.Fatalf("expected 1234567, got %v %v", v, err)
+	}
+	if v, err := european.parseFloat("1234,5"); err != nil || v != 1234.5 {
+		t.Fatalf("expected 1234.5, got %v %v", v, err)
+	}
+
+	ambiguous := NumberOption{Thousands: '.'}
+	if err := ambiguous.check(); err == nil {
+		t.Fatalf("thousands separator should not be the decimal mark")
+	}
+	if _, err := ReadCsv(strings.NewReader("a\n1.5\n"), ReadCsvOption{Number: ambiguous}); err == nil {
+		t.Fatalf("expected error of ambiguous number option")
+	}
+}
+
+func TestReadCsvNumber(t *testing.T) {
+	input := "amount;rate;price\n\"1.234,50\";12%;(¥99,00)\n\"2.000\";5,5%;¥1\n"
+	df, err := ReadCsv(strings.NewReader(input), ReadCsvOption{
+		Separator: ';',
+		Number:    NumberOption{Thousands: '.', Decimal: ',', Percent: true, Currencies: []string{"¥"}, AccountingNegative: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.DTypes(), []reflect.Kind{reflect.Float64, reflect.Float64, reflect.Float64}) {
+		t.Fatalf("unexpected dtypes: %v", df.DTypes())
+	}
+	if df.Val(0, "amount") != 1234.5 || df.Val(1, "rate") != 0.055 || df.Val(0, "price") != -99.0 {
+		t.Fatalf("unexpected values: %v", df.Seriess().Slice())
+	}
+}
@@ -0,0 +1,304 @@
+package pandat
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OdsWriter writes dataframes into sheets of one OpenDocument spreadsheet, e.g.
+//
+//	w := NewOdsWriter()
+//	_ = w.Write(orders, WriteOdsOption{Sheet: "orders"})
+//	_ = w.Write(customers, WriteOdsOption{Sheet: "customers"})
+//	err := w.Save("report.ods")
+type OdsWriter struct {
+	sheets []odsTable
+}
+
+// odsTable is a sheet rendered into a table:table element
+type odsTable struct {
+	name string
+	xml  []byte
+}
+
+// NewOdsWriter returns a writer of a new spreadsheet
+func NewOdsWriter() *OdsWriter {
+	return &OdsWriter{}
+}
+
+// Write writes the header and values of df into a new sheet named option.Sheet,
+// numbers, bools and times are written as typed cells and null values are empty cells.
+// Consecutive equal cells and rows are written once with their repeat counts.
+func (w *OdsWriter) Write(df *DataFrame[any], option WriteOdsOption) error {
+	return w.write(dataFrameExportColumns(df), option)
+}
+
+// WriteFrame is like Write but writes a frame
+func (w *OdsWriter) WriteFrame(f *Frame, option WriteOdsOption) error {
+	return w.write(frameExportColumns(f), option)
+}
+
+func (w *OdsWriter) write(columns exportColumns, option WriteOdsOption) error {
+	sheet := option.Sheet
+	if sheet == "" {
+		sheet = defaultXlsxSheet
+	}
+	for _, table := range w.sheets {
+		if table.name == sheet {
+			return fmt.Errorf("pandat: sheet %s already exists", sheet)
+		}
+	}
+	if option.Index {
+		columns = columns.withIndex(option.IndexLabel)
+	}
+	ncols := len(columns.names)
+
+	var b bytes.Buffer
+	b.WriteString(`<table:table table:name="`)
+	_ = xml.EscapeText(&b, []byte(sheet))
+	b.WriteString(`">`)
+	if ncols > 0 {
+		fmt.Fprintf(&b, `<table:table-column table:number-columns-repeated="%d"/>`, ncols)
+	}
+
+	header := make([]string, 0, ncols)
+	for _, name := range columns.names {
+		header = append(header, odsCell(name))
+	}
+	var (
+		row     = odsRow(header)
+		repeat  = 1
+		cells   = make([]string, ncols)
+		written = false
+	)
+	flush := func() {
+		if repeat > 1 {
+			b.WriteString(strings.Replace(row, "<table:table-row>", fmt.Sprintf(`<table:table-row table:number-rows-repeated="%d">`, repeat), 1))
+		} else {
+			b.WriteString(row)
+		}
+	}
+	for nrow := 0; nrow < columns.nrows; nrow++ {
+		for ncol, value := range columns.values {
+			cells[ncol] = odsCell(value(nrow))
+		}
+		next := odsRow(cells)
+		if written && next == row {
+			repeat++
+			continue
+		}
+		flush()
+		row, repeat, written = next, 1, true
+	}
+	flush()
+	b.WriteString(`</table:table>`)
+
+	w.sheets = append(w.sheets, odsTable{name: sheet, xml: b.Bytes()})
+	return nil
+}
+
+// odsRow renders cells into a table:table-row, consecutive equal cells are written once
+func odsRow(cells []string) string {
+	var b strings.Builder
+	b.WriteString("<table:table-row>")
+	for i := 0; i < len(cells); {
+		j := i + 1
+		for j < len(cells) && cells[j] == cells[i] {
+			j++
+		}
+		if j-i > 1 {
+			b.WriteString(strings.Replace(cells[i], "<table:table-cell", fmt.Sprintf(`<table:table-cell table:number-columns-repeated="%d"`, j-i), 1))
+		} else {
+			b.WriteString(cells[i])
+		}
+		i = j
+	}
+	b.WriteString("</table:table-row>")
+	return b.String()
+}
+
+// odsCell renders a value into a typed table:table-cell
+func odsCell(val any) string {
+	val = xlsxValue(val)
+	switch v := val.(type) {
+	case nil:
+		return "<table:table-cell/>"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s := fmt.Sprint(v)
+		return `<table:table-cell office:value-type="float" office:value="` + s + `"><text:p>` + s + `</text:p></table:table-cell>`
+	case float32:
+		return odsFloatCell(float64(v))
+	case float64:
+		return odsFloatCell(v)
+	case bool:
+		return `<table:table-cell office:value-type="boolean" office:boolean-value="` + strconv.FormatBool(v) + `"><text:p>` +
+			strings.ToUpper(strconv.FormatBool(v)) + `</text:p></table:table-cell>`
+	case time.Time:
+		style, layout := "ce1", "2006-01-02"
+		if !v.Equal(v.Truncate(24 * time.Hour)) {
+			style, layout = "ce2", "2006-01-02T15:04:05"
+		}
+		return `<table:table-cell table:style-name="` + style + `" office:value-type="date" office:date-value="` + v.Format(layout) + `"><text:p>` +
+			formatNativeValue(v) + `</text:p></table:table-cell>`
+	default:
+		return `<table:table-cell office:value-type="string"><text:p>` + escapeOdsText(fmt.Sprint(v)) + `</text:p></table:table-cell>`
+	}
+}
+
+func odsFloatCell(v float64) string {
+	if math.IsNaN(v) {
+		return "<table:table-cell/>"
+	}
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	return `<table:table-cell office:value-type="float" office:value="` + s + `"><text:p>` + s + `</text:p></table:table-cell>`
+}
+
+// escapeOdsText escapes a string for a text:p, spaces which would be collapsed, tabs and line breaks are written as elements
+func escapeOdsText(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); {
+		j := i + 1
+		switch s[i] {
+		case ' ':
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+			n := j - i
+			// a single space between words is kept, leading, trailing and repeated spaces would be collapsed
+			if i > 0 && j < len(s) && s[i-1] != '\t' && s[i-1] != '\n' {
+				b.WriteByte(' ')
+				n--
+			}
+			if n == 1 {
+				b.WriteString("<text:s/>")
+			} else if n > 1 {
+				fmt.Fprintf(&b, `<text:s text:c="%d"/>`, n)
+			}
+		case '\t':
+			b.WriteString("<text:tab/>")
+		case '\n':
+			b.WriteString("<text:line-break/>")
+		default:
+			for j < len(s) && s[j] != ' ' && s[j] != '\t' && s[j] != '\n' {
+				j++
+			}
+			_ = xml.EscapeText(&b, []byte(s[i:j]))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// WriteTo writes the spreadsheet to out
+func (w *OdsWriter) WriteTo(out io.Writer) (int64, error) {
+	counter := &countWriter{w: out}
+	z := zip.NewWriter(counter)
+	// the mimetype must be the first entry and not compressed
+	mimetype, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return counter.n, err
+	}
+	if _, err := io.WriteString(mimetype, odsMimeType); err != nil {
+		return counter.n, err
+	}
+	for _, entry := range []struct{ name, content string }{
+		{"META-INF/manifest.xml", odsManifest},
+		{"styles.xml", odsStyles},
+	} {
+		f, err := z.Create(entry.name)
+		if err != nil {
+			return counter.n, err
+		}
+		if _, err := io.WriteString(f, entry.content); err != nil {
+			return counter.n, err
+		}
+	}
+
+	content, err := z.Create("content.xml")
+	if err != nil {
+		return counter.n, err
+	}
+	if _, err := io.WriteString(content, odsContentHead); err != nil {
+		return counter.n, err
+	}
+	sheets := w.sheets
+	if len(sheets) == 0 {
+		// a spreadsheet has at least one sheet
+		sheets = []odsTable{{xml: []byte(`<table:table table:name="` + defaultXlsxSheet + `"/>`)}}
+	}
+	for _, sheet := range sheets {
+		if _, err := content.Write(sheet.xml); err != nil {
+			return counter.n, err
+		}
+	}
+	if _, err := io.WriteString(content, odsContentTail); err != nil {
+		return counter.n, err
+	}
+	err = z.Close()
+	return counter.n, err
+}
+
+// Save writes the spreadsheet to the file of given path
+func (w *OdsWriter) Save(filepath string) error {
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if _, err := w.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// countWriter counts bytes written to w
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+
+const odsStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" office:version="1.2"/>`
+
+// odsContentHead declares styles of dates "ce1" and times "ce2" like "2024-01-02 15:04:05"
+const odsContentHead = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" office:version="1.2">` +
+	`<office:automatic-styles>` +
+	`<number:date-style style:name="N1"><number:year number:style="long"/><number:text>-</number:text>` +
+	`<number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/></number:date-style>` +
+	`<number:date-style style:name="N2"><number:year number:style="long"/><number:text>-</number:text>` +
+	`<number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/><number:text> </number:text>` +
+	`<number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/><number:text>:</number:text>` +
+	`<number:seconds number:style="long"/></number:date-style>` +
+	`<style:style style:name="ce1" style:family="table-cell" style:data-style-name="N1"/>` +
+	`<style:style style:name="ce2" style:family="table-cell" style:data-style-name="N2"/>` +
+	`</office:automatic-styles><office:body><office:spreadsheet>`
+
+const odsContentTail = `</office:spreadsheet></office:body></office:document-content>`
@@ -0,0 +1,53 @@
+package pandat
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallel calls fn with 0 to n-1 in given number of goroutines, workers <= 0 means runtime.NumCPU().
+// All calls are finished before returning, the error of the smallest i is returned so that the result is deterministic.
+func parallel(n, workers int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	errs := make([]error, n)
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			if errs[i] = fn(i); errs[i] != nil {
+				return errs[i]
+			}
+		}
+		return nil
+	}
+
+	var (
+		wg    sync.WaitGroup
+		tasks = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
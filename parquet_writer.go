@@ -0,0 +1,367 @@
+package pandat
+
+import (
+	"fmt"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/writer"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// parquetBatchRows is the number of rows encoded into pages at a time
+const parquetBatchRows = 8192
+
+// parquetColumn is an optional leaf column of the schema,
+// value returns the i-th value converted into the physical type of the column or nil if null
+type parquetColumn struct {
+	name    string
+	element *parquet.SchemaElement
+	value   func(i int) any
+}
+
+func dataFrameParquetColumns[E any](d *DataFrame[E]) []parquetColumn {
+	columns := make([]parquetColumn, 0, len(d.seriess))
+	for i, series := range d.seriess {
+		columns = append(columns, seriesParquetColumn(i, series))
+	}
+	return columns
+}
+
+// frameParquetColumns returns columns of a frame, values of known types are read without boxing the whole column
+func frameParquetColumns(f *Frame) []parquetColumn {
+	columns := make([]parquetColumn, 0, len(f.columns))
+	for i, column := range f.columns {
+		switch s := column.(type) {
+		case *Series[int64]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		case *Series[uint64]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		case *Series[float64]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		case *Series[string]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		case *Series[bool]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		case *Series[time.Time]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		case *Series[any]:
+			columns = append(columns, seriesParquetColumn(i, s))
+		default:
+			columns = append(columns, seriesParquetColumn(i, column.Any()))
+		}
+	}
+	return columns
+}
+
+// seriesParquetColumn returns the nth column of the schema, named like "C0" internally so that
+// names which are not valid identifiers are kept, the physical type is decided by the dtype of non-nil values.
+// Unsigned integers are annotated as UINT_8 to UINT_64 and times are written as TIMESTAMP_MICROS,
+// values of other types are written as strings.
+func seriesParquetColumn[E any](n int, s *Series[E]) parquetColumn {
+	element := &parquet.SchemaElement{
+		Name:           "C" + strconv.Itoa(n),
+		RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+	}
+	var typ parquet.Type
+	switch dtype := parquetDType(s); dtype {
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		typ = parquet.Type_INT32
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		typ = parquet.Type_INT32
+		element.ConvertedType = parquet.ConvertedTypePtr(map[reflect.Kind]parquet.ConvertedType{
+			reflect.Uint8:  parquet.ConvertedType_UINT_8,
+			reflect.Uint16: parquet.ConvertedType_UINT_16,
+			reflect.Uint32: parquet.ConvertedType_UINT_32,
+		}[dtype])
+	case reflect.Int, reflect.Int64:
+		typ = parquet.Type_INT64
+	case reflect.Uint, reflect.Uint64:
+		typ = parquet.Type_INT64
+		element.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_64)
+	case reflect.Struct:
+		if !parquetIsTime(s) {
+			typ = parquet.Type_BYTE_ARRAY
+			element.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+			break
+		}
+		typ = parquet.Type_INT64
+		element.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+	case reflect.Float32:
+		typ = parquet.Type_FLOAT
+	case reflect.Float64:
+		typ = parquet.Type_DOUBLE
+	case reflect.Bool:
+		typ = parquet.Type_BOOLEAN
+	default:
+		typ = parquet.Type_BYTE_ARRAY
+		element.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	}
+	element.Type = parquet.TypePtr(typ)
+
+	column := parquetColumn{name: s.name, element: element}
+	switch elements := any(s.elements).(type) {
+	case []int64:
+		if typ == parquet.Type_INT64 {
+			column.value = func(i int) any { return elements[i] }
+		}
+	case []int:
+		if typ == parquet.Type_INT64 {
+			column.value = func(i int) any { return int64(elements[i]) }
+		}
+	case []uint64:
+		if typ == parquet.Type_INT64 {
+			column.value = func(i int) any { return int64(elements[i]) }
+		}
+	case []time.Time:
+		if typ == parquet.Type_INT64 {
+			column.value = func(i int) any { return elements[i].UnixMicro() }
+		}
+	case []float64:
+		if typ == parquet.Type_DOUBLE {
+			column.value = func(i int) any {
+				if math.IsNaN(elements[i]) {
+					return nil
+				}
+				return elements[i]
+			}
+		}
+	case []string:
+		if typ == parquet.Type_BYTE_ARRAY {
+			column.value = func(i int) any { return elements[i] }
+		}
+	case []bool:
+		if typ == parquet.Type_BOOLEAN {
+			column.value = func(i int) any { return elements[i] }
+		}
+	}
+	if column.value == nil {
+		column.value = func(i int) any { return parquetValue(s.elements[i], typ) }
+	}
+	return column
+}
+
+// parquetDType is the dtype of s ignoring nil values, so that nullable columns keep their types
+func parquetDType[E any](s *Series[E]) reflect.Kind {
+	elements, ok := any(s.elements).([]any)
+	if !ok {
+		return s.DType()
+	}
+	dtype := reflect.Invalid
+	for _, val := range elements {
+		if val == nil {
+			continue
+		}
+		if kind := reflect.ValueOf(val).Kind(); dtype == reflect.Invalid {
+			dtype = kind
+		} else if dtype != kind {
+			return reflect.Interface
+		}
+	}
+	return dtype
+}
+
+// parquetIsTime returns true if non-nil values of s are all time.Time
+func parquetIsTime[E any](s *Series[E]) bool {
+	if _, ok := any(s.elements).([]time.Time); ok {
+		return true
+	}
+	found := false
+	for _, val := range s.elements {
+		if any(val) == nil {
+			continue
+		}
+		if _, ok := any(val).(time.Time); !ok {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// parquetValue converts val into the Go type of the physical type typ, nil is returned for null values
+func parquetValue(val any, typ parquet.Type) any {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case int64:
+		if typ == parquet.Type_INT64 {
+			return v
+		}
+	case float64:
+		if math.IsNaN(v) {
+			return nil
+		} else if typ == parquet.Type_DOUBLE {
+			return v
+		}
+	case string:
+		if typ == parquet.Type_BYTE_ARRAY {
+			return v
+		}
+	case bool:
+		if typ == parquet.Type_BOOLEAN {
+			return v
+		}
+	}
+	if isNull(val) {
+		return nil
+	}
+
+	ref := reflect.Indirect(reflect.ValueOf(val))
+	switch typ {
+	case parquet.Type_INT32, parquet.Type_INT64:
+		var n int64
+		switch ref.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = ref.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			// unsigned values beyond the signed range are kept by the bits, read by the UINT converted types
+			n = int64(ref.Uint())
+		case reflect.Struct:
+			if t, ok := ref.Interface().(time.Time); ok {
+				n = t.UnixMicro()
+			}
+		}
+		if typ == parquet.Type_INT32 {
+			return int32(n)
+		}
+		return n
+	case parquet.Type_FLOAT:
+		return float32(ref.Float())
+	case parquet.Type_DOUBLE:
+		return ref.Float()
+	case parquet.Type_BOOLEAN:
+		return ref.Bool()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// writeParquet writes nrows rows of columns, pages of each column are encoded from its values in batches of rows
+// instead of marshaling rows into structs.
+// It relies on how parquet-go v1.6.2 writes rows: Flush(false) splits pw.Objs between goroutines and passes
+// each part to pw.MarshalFunc, and encodes the returned tables into pages without ending the row group.
+// Check these when upgrading parquet-go.
+func writeParquet(op string, f io.Writer, columns []parquetColumn, nrows int, option WriteParquetOption) error {
+	codec, ok := parquetCodecs[option.Compression]
+	if !ok {
+		return fmt.Errorf("pandat: unsupported parquet compression %d", option.Compression)
+	}
+	for name := range option.Dictionary {
+		found := false
+		for _, column := range columns {
+			found = found || column.name == name
+		}
+		if !found {
+			return newError(op, -1, name, ErrColumnNotFound, nil)
+		}
+	}
+
+	elements := make([]*parquet.SchemaElement, 0, len(columns)+1)
+	elements = append(elements, &parquet.SchemaElement{
+		Name:           "Parquet_go_root",
+		RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+		NumChildren:    parquetInt32Ptr(int32(len(columns))),
+	})
+	for _, column := range columns {
+		elements = append(elements, column.element)
+	}
+	sh := schema.NewSchemaHandlerFromSchemaList(elements)
+	for i, column := range columns {
+		info := sh.Infos[i+1]
+		info.ExName = column.name
+		info.Encoding = parquet.Encoding_PLAIN
+		dictionary, ok := option.Dictionary[column.name]
+		if (ok && dictionary) || (!ok && *column.element.Type == parquet.Type_BYTE_ARRAY) {
+			info.Encoding = parquet.Encoding_PLAIN_DICTIONARY
+		}
+	}
+	sh.CreateInExMap()
+
+	w := writerfile.NewWriterFile(f)
+	defer w.Close()
+
+	parallelism := option.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+	pw, err := writer.NewParquetWriter(w, nil, int64(parallelism))
+	if err != nil {
+		return err
+	}
+	pw.SchemaHandler = sh
+	pw.Footer.Schema = append(pw.Footer.Schema, sh.SchemaElements...)
+	pw.CompressionType = codec
+	if option.RowGroupSize > 0 {
+		pw.RowGroupSize = option.RowGroupSize
+	}
+	if option.PageSize > 0 {
+		pw.PageSize = option.PageSize
+	}
+	keys := make([]string, 0, len(option.Metadata))
+	for key := range option.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := option.Metadata[key]
+		pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata, &parquet.KeyValue{Key: key, Value: &value})
+	}
+
+	// the writer splits pw.Objs between goroutines, they are offsets of rows from the first row of the batch
+	offsets := make([]any, parquetBatchRows)
+	for i := range offsets {
+		offsets[i] = i
+	}
+	var first int
+	pw.MarshalFunc = func(src []any, sh *schema.SchemaHandler) (*map[string]*layout.Table, error) {
+		from, n := first+src[0].(int), len(src)
+		tables := make(map[string]*layout.Table, len(columns))
+		for i, column := range columns {
+			var (
+				values = make([]any, n)
+				levels = make([]int32, n)
+			)
+			for j := range values {
+				if v := column.value(from + j); v != nil {
+					values[j], levels[j] = v, 1
+				}
+			}
+			path := sh.IndexMap[int32(i+1)]
+			tables[path] = &layout.Table{
+				RepetitionType:     parquet.FieldRepetitionType_OPTIONAL,
+				Schema:             column.element,
+				Path:               common.StrToPath(path),
+				MaxDefinitionLevel: 1,
+				Values:             values,
+				DefinitionLevels:   levels,
+				RepetitionLevels:   make([]int32, n),
+				Info:               sh.Infos[i+1],
+			}
+		}
+		return &tables, nil
+	}
+	for first = 0; first < nrows; first += parquetBatchRows {
+		n := nrows - first
+		if n > parquetBatchRows {
+			n = parquetBatchRows
+		}
+		pw.Objs = offsets[:n]
+		if err := pw.Flush(false); err != nil {
+			return err
+		}
+	}
+	return pw.WriteStop()
+}
+
+func parquetInt32Ptr(v int32) *int32 {
+	return &v
+}
@@ -1,9 +1,14 @@
 package pandat
 
 import (
+	"bufio"
 	"encoding/csv"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"strconv"
 )
 
 type ReadCsvOption struct {
@@ -11,6 +16,41 @@ type ReadCsvOption struct {
 	AlwaysQuotes     bool
 	TrimLeadingSpace bool
 	Separator        rune
+	// Encoding is the text encoding of the file, e.g. "gbk", "gb18030", "utf-16", or EncodingAuto to detect it.
+	// UTF-8 is used by default, and the byte order mark is always stripped.
+	Encoding string
+	// Comment is the character which starts a comment line, 0 means no comment
+	Comment rune
+	// DTypes specifies dtypes of columns by name instead of inferring them from values,
+	// supported dtypes are reflect.Float64, reflect.Int64, reflect.Uint64, reflect.Bool, reflect.String and reflect.Interface
+	DTypes map[string]reflect.Kind
+	// NaValues are extra values which are read as null besides Inference.NullValues, e.g. "N/A", "-"
+	NaValues []string
+	// UseCols are names (string) or indexes (int) of columns to read, all columns are read if empty.
+	// Columns are always in the same order as the file.
+	UseCols []any
+	// SkipRows is the number of lines to skip at the beginning of the file
+	SkipRows int
+	// HeaderRow is the index of the header line after SkipRows, lines before the header are skipped
+	HeaderRow int
+	// NRows is the number of rows to read excluding the header, 0 means all rows
+	NRows int
+	// Names are column names which replace the header, or name the columns of a NoHeader file
+	Names []string
+	// Header normalizes column names before UseCols and DTypes are applied
+	Header HeaderOption
+	// Number describes how numbers are formatted
+	Number NumberOption
+	// Inference is the vocabulary of bool and null values
+	Inference TypeInferenceOption
+	// BadLines is the action for lines with a different number of fields from the header
+	BadLines BadLineAction
+	// OnBadLine is called with the line number and fields of each bad line if BadLines is BadLineWarn
+	OnBadLine func(line int, record []string)
+	// Workers is the number of goroutines to parse records and convert columns, 0 means runtime.NumCPU() and 1 means serially.
+	// Records are parsed concurrently only if Workers is greater than 1, which reads the whole input into memory first,
+	// otherwise they are parsed serially while reading. Output is the same for any number of workers.
+	Workers int
 }
 
 func ReadCsvPath(filepath string, option ReadCsvOption) (*DataFrame[any], error) {
@@ -22,6 +62,268 @@ func ReadCsvPath(filepath string, option ReadCsvOption) (*DataFrame[any], error)
 }
 
 func ReadCsv(r io.Reader, option ReadCsvOption) (*DataFrame[any], error) {
+	schema, data, err := readCsvColumns(r, option)
+	if err != nil {
+		return nil, err
+	} else if schema == nil {
+		// empty csv file
+		return NewDataFrame[any](), nil
+	}
+	return readColumns(option.parser(), schema.names, data, schema.dtypes, 0, option.Workers)
+}
+
+// ReadCsvTyped is like ReadCsv but stores each column in its inferred type without boxing,
+// see Frame.Schema for the inferred schema
+func ReadCsvTyped(r io.Reader, option ReadCsvOption) (*Frame, error) {
+	schema, data, err := readCsvColumns(r, option)
+	if err != nil {
+		return nil, err
+	} else if schema == nil {
+		// empty csv file
+		return NewFrame(), nil
+	}
+	return readTypedColumns(option.parser(), schema.names, data, schema.dtypes, 0, option.Workers)
+}
+
+// readCsvColumns reads used columns of a csv file, schema is nil if the file is empty
+func readCsvColumns(r io.Reader, option ReadCsvOption) (*csvSchema, [][]string, error) {
+	if err := option.Number.check(); err != nil {
+		return nil, nil, err
+	}
+	r, err := decodeReader(r, option.Encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err = skipLines(r, option.SkipRows+option.HeaderRow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records [][]string
+	if option.NRows > 0 {
+		// stop reading as soon as enough rows are read
+		limit := option.NRows
+		if !option.NoHeader {
+			limit++
+		}
+		records, err = newCsvRecordReader(r, option, 0, option.SkipRows+option.HeaderRow).ReadAll(limit)
+	} else if option.Workers <= 1 {
+		records, err = newCsvRecordReader(r, option, 0, option.SkipRows+option.HeaderRow).ReadAll(0)
+	} else {
+		records, err = readCsvParallel(r, option)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	var header []string
+	if !option.NoHeader {
+		header = records[0]
+		records = records[1:]
+	} else {
+		header = defaultHeader(len(records[0]))
+	}
+
+	schema, err := newCsvSchema(header, option)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, schema.columns(records), nil
+}
+
+// CsvChunkReader reads a csv file chunk by chunk, see ReadCsvChunked
+type CsvChunkReader struct {
+	reader    *csvRecordReader
+	option    ReadCsvOption
+	parser    valueParser
+	chunkRows int
+	header    []string
+	schema    *csvSchema
+	rows      int
+}
+
+// ReadCsvChunked returns a reader which reads at most chunkRows rows into a dataframe each time,
+// so that a large file can be processed in constant memory.
+// Dtypes of columns are specified by option.DTypes or inferred from the first chunk,
+// and all chunks share the same dtypes.
+func ReadCsvChunked(r io.Reader, option ReadCsvOption, chunkRows int) (*CsvChunkReader, error) {
+	if chunkRows <= 0 {
+		return nil, errors.New("pandat: chunkRows must be positive, got " + strconv.Itoa(chunkRows))
+	}
+	if err := option.Number.check(); err != nil {
+		return nil, err
+	}
+
+	r, err := decodeReader(r, option.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	r, err = skipLines(r, option.SkipRows+option.HeaderRow)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := newCsvRecordReader(r, option, 0, option.SkipRows+option.HeaderRow)
+	reader.reader.ReuseRecord = true
+
+	c := &CsvChunkReader{
+		reader:    reader,
+		option:    option,
+		parser:    option.parser(),
+		chunkRows: chunkRows,
+	}
+	if !option.NoHeader {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return c, nil
+		} else if err != nil {
+			return nil, err
+		}
+		c.header = append([]string(nil), header...)
+		if c.schema, err = newCsvSchema(c.header, option); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Next returns the next chunk, io.EOF is returned if there are no more rows
+func (c *CsvChunkReader) Next() (*DataFrame[any], error) {
+	limit := c.chunkRows
+	if c.option.NRows > 0 && c.option.NRows-c.rows < limit {
+		limit = c.option.NRows - c.rows
+	}
+
+	var data [][]string
+	n := 0
+	for ; n < limit; n++ {
+		record, err := c.reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if c.schema == nil {
+			c.header = defaultHeader(len(record))
+			if c.schema, err = newCsvSchema(c.header, c.option); err != nil {
+				return nil, err
+			}
+		}
+		if data == nil {
+			data = make([][]string, len(c.schema.indexes))
+			for i := range data {
+				data[i] = make([]string, 0, limit)
+			}
+		}
+		c.schema.appendRecord(data, record)
+	}
+
+	if n == 0 {
+		return nil, io.EOF
+	}
+
+	// dtypes determined by the first chunk are written back to the schema and used by all chunks
+	df, err := readColumns(c.parser, c.schema.names, data, c.schema.dtypes, c.rows, c.option.Workers)
+	c.rows += n
+	return df, err
+}
+
+// DTypes returns dtypes of columns which are determined by the first chunk, nil before the first chunk is read
+func (c *CsvChunkReader) DTypes() []reflect.Kind {
+	if c.rows == 0 {
+		return nil
+	}
+	return c.schema.dtypes
+}
+
+// Names returns column names, nil if the header has not been read
+func (c *CsvChunkReader) Names() []string {
+	if c.schema == nil {
+		return nil
+	}
+	return c.schema.names
+}
+
+// csvRecordReader reads records and applies the bad line action of the option
+type csvRecordReader struct {
+	reader *csv.Reader
+	option ReadCsvOption
+	// fields is the expected number of fields, 0 means the number of fields of the first record
+	fields int
+	// lineOffset is the number of lines before the input, which is added to line numbers
+	lineOffset int
+}
+
+func newCsvRecordReader(r io.Reader, option ReadCsvOption, fields int, lineOffset int) *csvRecordReader {
+	reader := newCsvReader(r, option)
+	// the number of fields is checked by csvRecordReader
+	reader.FieldsPerRecord = -1
+	return &csvRecordReader{
+		reader:     reader,
+		option:     option,
+		fields:     fields,
+		lineOffset: lineOffset,
+	}
+}
+
+// Read returns the next record with the expected number of fields
+func (r *csvRecordReader) Read() ([]string, error) {
+	for {
+		record, err := r.reader.Read()
+		if err != nil {
+			var e *csv.ParseError
+			if errors.As(err, &e) {
+				e.StartLine += r.lineOffset
+				e.Line += r.lineOffset
+			}
+			return nil, err
+		}
+
+		if r.fields == 0 {
+			r.fields = len(record)
+		}
+		if len(record) == r.fields {
+			return record, nil
+		}
+
+		line, _ := r.reader.FieldPos(0)
+		line += r.lineOffset
+		cause := &csv.ParseError{StartLine: line, Line: line, Column: 1, Err: csv.ErrFieldCount}
+		record, err = handleBadLine("ReadCsv", r.option.BadLines, r.option.OnBadLine, record, r.fields, line, cause)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			return record, nil
+		}
+	}
+}
+
+// ReadAll reads at most limit records, 0 means all records
+func (r *csvRecordReader) ReadAll(limit int) ([][]string, error) {
+	var records [][]string
+	for limit <= 0 || len(records) < limit {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (o ReadCsvOption) parser() valueParser {
+	return newValueParser(o.Number, o.Inference, o.NaValues...)
+}
+
+func newCsvReader(r io.Reader, option ReadCsvOption) *csv.Reader {
 	reader := csv.NewReader(r)
 	if option.Separator == 0 {
 		reader.Comma = ','
@@ -29,24 +331,123 @@ func ReadCsv(r io.Reader, option ReadCsvOption) (*DataFrame[any], error) {
 		reader.Comma = option.Separator
 	}
 
+	reader.Comment = option.Comment
 	reader.LazyQuotes = !option.AlwaysQuotes
 	reader.TrimLeadingSpace = option.TrimLeadingSpace
+	return reader
+}
 
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
+// skipLines discards the first n lines of r
+func skipLines(r io.Reader, n int) (io.Reader, error) {
+	if n <= 0 {
+		return r, nil
 	}
 
-	if len(records) == 0 {
-		// empty csv file
-		return NewDataFrame[any](), nil
+	br := bufio.NewReader(r)
+	for i := 0; i < n; {
+		_, err := br.ReadSlice('\n')
+		switch err {
+		case nil:
+			i++
+		case bufio.ErrBufferFull:
+			// the line is longer than the buffer, keep reading the same line
+		case io.EOF:
+			return br, nil
+		default:
+			return nil, err
+		}
+	}
+	return br, nil
+}
+
+// csvSchema describes which fields of records are read and how they are converted
+type csvSchema struct {
+	names   []string
+	indexes []int
+	dtypes  []reflect.Kind
+}
+
+func newCsvSchema(header []string, option ReadCsvOption) (*csvSchema, error) {
+	if option.Names != nil {
+		if len(option.Names) != len(header) {
+			return nil, newError("ReadCsv", -1, "", ErrLengthMismatch,
+				fmt.Errorf("%d names for %d columns", len(option.Names), len(header)))
+		}
+		header = option.Names
 	}
+	header = option.Header.normalize(header)
 
-	data := make([][]string, len(records[0]))
-	for _, row := range records {
-		for ncol, val := range row {
-			data[ncol] = append(data[ncol], val)
+	var indexes []int
+	if len(option.UseCols) == 0 {
+		indexes = make([]int, 0, len(header))
+		for i := range header {
+			indexes = append(indexes, i)
+		}
+	} else {
+		used := make(map[int]struct{}, len(option.UseCols))
+		for _, col := range option.UseCols {
+			i, err := csvColumnIndex(header, col)
+			if err != nil {
+				return nil, err
+			}
+			used[i] = struct{}{}
+		}
+		// keep the order of the file
+		indexes = make([]int, 0, len(used))
+		for i := range header {
+			if _, ok := used[i]; ok {
+				indexes = append(indexes, i)
+			}
 		}
 	}
-	return ReadSlice(data, !option.NoHeader), nil
+
+	schema := &csvSchema{
+		names:   make([]string, 0, len(indexes)),
+		indexes: indexes,
+		dtypes:  make([]reflect.Kind, 0, len(indexes)),
+	}
+	for _, i := range indexes {
+		schema.names = append(schema.names, header[i])
+		// reflect.Invalid will be determined by values
+		schema.dtypes = append(schema.dtypes, option.DTypes[header[i]])
+	}
+	return schema, nil
+}
+
+func csvColumnIndex(header []string, col any) (int, error) {
+	switch c := col.(type) {
+	case int:
+		if c < 0 || c >= len(header) {
+			return 0, newError("ReadCsv", -1, strconv.Itoa(c), ErrColumnNotFound, nil)
+		}
+		return c, nil
+	case string:
+		for i, name := range header {
+			if name == c {
+				return i, nil
+			}
+		}
+		return 0, newError("ReadCsv", -1, c, ErrColumnNotFound, nil)
+	default:
+		return 0, newError("ReadCsv", -1, fmt.Sprint(col), ErrColumnNotFound, fmt.Errorf("unsupported type %T", col))
+	}
+}
+
+// columns transposes records into columns
+func (s *csvSchema) columns(records [][]string) [][]string {
+	data := make([][]string, len(s.indexes))
+	for i := range data {
+		data[i] = make([]string, 0, len(records))
+	}
+	for _, record := range records {
+		s.appendRecord(data, record)
+	}
+	return data
+}
+
+// appendRecord appends used fields of the record to columns
+func (s *csvSchema) appendRecord(data [][]string, record []string) {
+	for i, index := range s.indexes {
+		data[i] = append(data[i], record[index])
+	}
 }
@@ -0,0 +1,160 @@
+package pandat
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// minCsvSegmentSize is the minimum bytes of input parsed by a worker, smaller input is not worth splitting
+const minCsvSegmentSize = 1 << 20
+
+// readCsvParallel splits the input on record boundaries and parses the segments by option.Workers goroutines.
+// Records are returned in the same order as the input.
+func readCsvParallel(r io.Reader, option ReadCsvOption) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := option.Workers
+	parts := len(data) / minCsvSegmentSize
+	if parts > workers {
+		parts = workers
+	}
+	comma := option.Separator
+	if comma == 0 {
+		comma = ','
+	}
+	if parts <= 1 || comma >= utf8.RuneSelf || option.Comment >= utf8.RuneSelf {
+		return newCsvRecordReader(bytes.NewReader(data), option, 0, option.SkipRows+option.HeaderRow).ReadAll(0)
+	}
+
+	bounds, lines, fields := splitCsvRecords(data, byte(comma), byte(option.Comment), !option.AlwaysQuotes, option.TrimLeadingSpace, parts)
+	var (
+		segments = make([][][]string, len(bounds))
+		badLines = make([][]csvBadLine, len(bounds))
+		errs     = make([]error, len(bounds))
+	)
+	_ = parallel(len(bounds), workers, func(i int) error {
+		end := len(data)
+		if i+1 < len(bounds) {
+			end = bounds[i+1]
+		}
+		// bad lines are collected and reported in order of lines after parsing, OnBadLine is never called concurrently
+		segmentOption := option
+		segmentOption.OnBadLine = func(line int, record []string) {
+			badLines[i] = append(badLines[i], csvBadLine{line, record})
+		}
+		// every segment must have the same number of fields as the first record of the file
+		reader := newCsvRecordReader(bytes.NewReader(data[bounds[i]:end]), segmentOption, fields, option.SkipRows+option.HeaderRow+lines[i])
+		segments[i], errs[i] = reader.ReadAll(0)
+		return nil
+	})
+	// report like the serial reader, bad lines before the first error are reported
+	for i, err := range errs {
+		if option.OnBadLine != nil {
+			for _, bad := range badLines[i] {
+				option.OnBadLine(bad.line, bad.record)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	total := 0
+	for _, records := range segments {
+		total += len(records)
+	}
+	records := make([][]string, 0, total)
+	for _, segment := range segments {
+		records = append(records, segment...)
+	}
+	return records, nil
+}
+
+// csvBadLine is a bad line collected by a segment
+type csvBadLine struct {
+	line   int
+	record []string
+}
+
+// splitCsvRecords returns the start offsets of about parts segments which begin at record boundaries,
+// the number of lines before each segment and the number of fields of the first record.
+// Quotes are tracked like encoding/csv, so that newlines in quoted fields are never used as boundaries,
+// comment lines are ignored if comment is not 0.
+func splitCsvRecords(data []byte, comma, comment byte, lazyQuotes, trimLeadingSpace bool, parts int) ([]int, []int, int) {
+	var (
+		bounds     = []int{0}
+		lines      = []int{0}
+		step       = len(data) / parts
+		next       = step
+		nlines     = 0
+		fields     = 1
+		counted    = false
+		inQuotes   = false
+		fieldStart = true
+		lineEmpty  = true
+	)
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		if inQuotes {
+			switch {
+			case b == '"' && i+1 < len(data) && data[i+1] == '"':
+				// escaped quote
+				i++
+			case b == '"':
+				if i+1 == len(data) || !lazyQuotes {
+					inQuotes = false
+				} else if c := data[i+1]; c == comma || c == '\n' || c == '\r' {
+					inQuotes = false
+				}
+				// otherwise it is a bare quote in a lazy quoted field
+			case b == '\n':
+				nlines++
+			}
+			continue
+		}
+
+		switch {
+		case comment != 0 && b == comment && fieldStart && lineEmpty && (i == 0 || data[i-1] == '\n'):
+			// skip the comment line, the line break is handled as usual
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case b == '"' && fieldStart:
+			inQuotes = true
+			fieldStart = false
+			lineEmpty = false
+		case b == comma:
+			if !counted {
+				fields++
+			}
+			fieldStart = true
+			lineEmpty = false
+		case b == '\r' && i+1 < len(data) && data[i+1] == '\n':
+			// part of a line break
+		case b == '\n':
+			nlines++
+			// empty lines are skipped by encoding/csv
+			if !lineEmpty {
+				counted = true
+			}
+			if counted && i+1 >= next && i+1 < len(data) && len(bounds) < parts {
+				bounds = append(bounds, i+1)
+				lines = append(lines, nlines)
+				next = i + 1 + step
+			}
+			fieldStart = true
+			lineEmpty = true
+		case trimLeadingSpace && fieldStart && (b == ' ' || b == '\t'):
+			// leading spaces are trimmed, still at the start of a field
+			lineEmpty = false
+		default:
+			fieldStart = false
+			lineEmpty = false
+		}
+	}
+	return bounds, lines, fields
+}
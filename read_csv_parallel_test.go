@@ -0,0 +1,97 @@
+package pandat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReadCsvParallel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("id,name,score,flag\n")
+	for i := 0; i < 100000; i++ {
+		if i%1000 == 0 {
+			buf.WriteString("# comment with \"quote\n")
+		}
+		buf.WriteString(strconv.Itoa(i))
+		switch i % 4 {
+		case 0:
+			buf.WriteString(`,"multi`)
+			buf.WriteString("\n")
+			buf.WriteString(`line, ""quoted""",`)
+		case 1:
+			buf.WriteString(`,5" screen,`)
+		default:
+			buf.WriteString(",plain,")
+		}
+		buf.WriteString(strconv.FormatFloat(float64(i)/3, 'f', 3, 64))
+		buf.WriteString(",true\n")
+	}
+	input := buf.String()
+
+	serial, err := ReadCsv(strings.NewReader(input), ReadCsvOption{Workers: 1, Comment: '#'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	concurrent, err := ReadCsv(strings.NewReader(input), ReadCsvOption{Workers: 4, Comment: '#'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial.NRows() != 100000 || !reflect.DeepEqual(serial.Seriess().Slice(), concurrent.Seriess().Slice()) {
+		t.Fatalf("parallel result differs from serial result")
+	}
+	if !reflect.DeepEqual(serial.Names(), concurrent.Names()) || !reflect.DeepEqual(serial.DTypes(), concurrent.DTypes()) {
+		t.Fatalf("unexpected schema: %v %v", concurrent.Names(), concurrent.DTypes())
+	}
+
+	// line numbers of parse errors are counted from the beginning of the input
+	broken := input + "1,2\n"
+	_, err = ReadCsv(strings.NewReader(broken), ReadCsvOption{Workers: 4, Comment: '#'})
+	var e *csv.ParseError
+	if !errors.As(err, &e) || e.Line != strings.Count(input, "\n")+1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReadCsvParallelBadLines(t *testing.T) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("id,name,score\n")
+	for i := 0; i < 200000; i++ {
+		if i%20000 == 0 {
+			buf.WriteString("bad\n")
+		}
+		buf.WriteString(strconv.Itoa(i))
+		buf.WriteString(",some name,")
+		buf.WriteString(strconv.Itoa(i * 7))
+		buf.WriteString("\n")
+	}
+	input := buf.String()
+	if len(input) < 4*minCsvSegmentSize {
+		t.Fatalf("input is too small to be split into 4 segments: %d", len(input))
+	}
+
+	read := func(workers int) []int {
+		// lines are appended without locking, the race detector reports concurrent calls
+		var lines []int
+		df, err := ReadCsv(strings.NewReader(input), ReadCsvOption{
+			Workers:   workers,
+			BadLines:  BadLineWarn,
+			OnBadLine: func(line int, record []string) { lines = append(lines, line) },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if df.NRows() != 200000 {
+			t.Fatalf("expected 200000 rows, got %d", df.NRows())
+		}
+		return lines
+	}
+	serial, concurrent := read(1), read(4)
+	if len(serial) != 10 || !reflect.DeepEqual(serial, concurrent) {
+		t.Fatalf("expected bad lines %v, got %v", serial, concurrent)
+	}
+}
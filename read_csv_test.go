@@ -1,14 +1,23 @@
 package pandat
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestReadCSV(t *testing.T) {
 	f, err := os.Open("/Users/tanyaofei/Desktop/测试数据/1.csv")
-	if err != nil {
+	if os.IsNotExist(err) {
+		t.Skip(err)
+	} else if err != nil {
 		panic(err)
 	}
 	defer f.Close()
@@ -20,8 +29,184 @@ func TestReadCSV(t *testing.T) {
 	fmt.Println(df)
 
 	out, err := os.Create("1.csv")
-	err = df.ToCsv(out)
+	err = df.ToCsv(out, WriteCSVOption{})
 	if err != nil {
 		panic(err)
 	}
 }
+
+func TestReadCsvChunked(t *testing.T) {
+	input := "a,b,c\n1,x,1.5\n2,y,2\n3,z,\n4,w,4.5\n5,v,5\n"
+	reader, err := ReadCsvChunked(strings.NewReader(input), ReadCsvOption{}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := 0
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(chunk.Names(), []string{"a", "b", "c"}) {
+			t.Fatalf("unexpected names: %v", chunk.Names())
+		}
+		rows += chunk.NRows()
+	}
+	if rows != 5 {
+		t.Fatalf("expected 5 rows, got %d", rows)
+	}
+	if dtypes := reader.DTypes(); !reflect.DeepEqual(dtypes, []reflect.Kind{reflect.Int64, reflect.String, reflect.Float64}) {
+		t.Fatalf("unexpected dtypes: %v", dtypes)
+	}
+
+	// the first chunk infers int64 for column a, a float in a later chunk can not be converted
+	reader, _ = ReadCsvChunked(strings.NewReader("a\n1\n2\n3.5\n"), ReadCsvOption{}, 2)
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	var e *Error
+	if _, err := reader.Next(); !errors.As(err, &e) || e.Row != 2 {
+		t.Fatalf("expected conversion error at row 2, got %v", err)
+	}
+
+	// dtypes can be supplied
+	reader, _ = ReadCsvChunked(strings.NewReader("a\n1\n2\n3.5\n"), ReadCsvOption{DTypes: map[string]reflect.Kind{"a": reflect.Float64}}, 2)
+	for {
+		if _, err := reader.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// records of bad lines are kept by the callback while the reader reuses its records
+	var bad [][]string
+	reader, _ = ReadCsvChunked(strings.NewReader("a,b\n1,2\n3,4,x\n5,6\n7,8,y\n9,10\n"), ReadCsvOption{BadLines: BadLineWarn, OnBadLine: func(line int, record []string) {
+		bad = append(bad, record)
+	}}, 2)
+	for {
+		if _, err := reader.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(bad, [][]string{{"3", "4", "x"}, {"7", "8", "y"}}) {
+		t.Fatalf("unexpected bad lines: %v", bad)
+	}
+}
+
+func TestReadCsvOption(t *testing.T) {
+	input := "report generated at 2022-04-01\n" +
+		"\n" +
+		"id,code,amount,note\n" +
+		"# comment line\n" +
+		"1,00123,N/A,a\n" +
+		"2,00456,3.5,b\n" +
+		"3,00789,-,c\n"
+
+	df, err := ReadCsv(strings.NewReader(input), ReadCsvOption{
+		SkipRows:  1,
+		HeaderRow: 1,
+		Comment:   '#',
+		NaValues:  []string{"N/A", "-"},
+		UseCols:   []any{"amount", 1},
+		DTypes:    map[string]reflect.Kind{"code": reflect.String},
+		NRows:     2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"code", "amount"}) || df.NRows() != 2 {
+		t.Fatalf("unexpected dataframe: %v %d", df.Names(), df.NRows())
+	}
+	if code := df.Val(0, "code"); code != "00123" {
+		t.Fatalf("expected code kept as string, got %v", code)
+	}
+	if amount, ok := df.Val(0, "amount").(float64); !ok || !math.IsNaN(amount) {
+		t.Fatalf("expected NaN amount, got %v", df.Val(0, "amount"))
+	}
+
+	df, err = ReadCsv(strings.NewReader("1,2\n3,4\n"), ReadCsvOption{NoHeader: true, Names: []string{"x", "y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"x", "y"}) || df.NRows() != 2 {
+		t.Fatalf("unexpected dataframe: %v %d", df.Names(), df.NRows())
+	}
+
+	if df, err := ReadCsv(strings.NewReader("a,b\n1,2\n"), ReadCsvOption{UseCols: []any{"c"}}); df != nil || !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound without dataframe, got %v %v", df, err)
+	}
+	if f, err := ReadCsvTyped(strings.NewReader("a,b\n1,2\n"), ReadCsvOption{UseCols: []any{"c"}}); f != nil || !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound without frame, got %v %v", f, err)
+	}
+	if df, err := ReadCsv(strings.NewReader(""), ReadCsvOption{}); err != nil || df.NCols() != 0 {
+		t.Fatalf("expected empty dataframe, got %v %v", df, err)
+	}
+}
+
+func TestReadCsvEncoding(t *testing.T) {
+	df := NewDataFrame(
+		NewSeries[any]("名称", "苹果", "香蕉"),
+		NewSeries[any]("数量", int64(1), int64(2)),
+	)
+
+	for _, encoding := range []string{"gbk", "gb18030", "utf-16le", "utf-16be", "utf-8"} {
+		buf := new(bytes.Buffer)
+		if err := df.ToCsv(buf, WriteCSVOption{Encoding: encoding, WriteBOM: encoding != "gbk"}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, option := range []ReadCsvOption{{Encoding: encoding}, {Encoding: EncodingAuto}} {
+			read, err := ReadCsv(bytes.NewReader(buf.Bytes()), option)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(read.Names(), df.Names()) || read.Val(1, "名称") != "香蕉" || read.Val(1, "数量") != int64(2) {
+				t.Fatalf("%s %s: unexpected dataframe: %v", encoding, option.Encoding, read.Names())
+			}
+		}
+	}
+
+	if err := df.ToCsv(new(bytes.Buffer), WriteCSVOption{Encoding: "gbk", WriteBOM: true}); err == nil {
+		t.Fatalf("expected error for gbk byte order mark")
+	}
+}
+
+func TestReadCsvBadLines(t *testing.T) {
+	input := "a,b\n1,2\n3\n4,5,6\n7,8\n"
+
+	_, err := ReadCsv(strings.NewReader(input), ReadCsvOption{})
+	var e *csv.ParseError
+	if !errors.Is(err, ErrBadLine) || !errors.As(err, &e) || e.Line != 3 {
+		t.Fatalf("expected bad line error at line 3, got %v", err)
+	}
+
+	df, err := ReadCsv(strings.NewReader(input), ReadCsvOption{BadLines: BadLineSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.NRows() != 2 || df.Val(1, "a") != int64(7) {
+		t.Fatalf("unexpected rows: %v", df.Seriess().Slice())
+	}
+
+	var lines []int
+	_, err = ReadCsv(strings.NewReader(input), ReadCsvOption{BadLines: BadLineWarn, OnBadLine: func(line int, record []string) {
+		lines = append(lines, line)
+	}})
+	if err != nil || !reflect.DeepEqual(lines, []int{3, 4}) {
+		t.Fatalf("unexpected bad lines: %v %v", lines, err)
+	}
+
+	df, err = ReadCsv(strings.NewReader(input), ReadCsvOption{BadLines: BadLinePad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.NRows() != 4 || df.Val(1, "b") != nil || df.Val(2, "b") != int64(5) {
+		t.Fatalf("unexpected rows: %v", df.Seriess().Slice())
+	}
+}
@@ -1,183 +1,182 @@
 package pandat
 
 import (
-	"math"
+	"errors"
 	"reflect"
 	"strconv"
 )
 
-func ReadMap(data map[string][]string) *DataFrame[any] {
-	seriess := make([]*Series[any], 0, len(data))
-	for name, values := range data {
-		seriess = append(seriess, NewSeries(name, asType(values, determineType(values))...))
-	}
-	return NewDataFrame(seriess...)
+// ReadSliceOption is the optional option of ReadMap and ReadSlice
+type ReadSliceOption struct {
+	Header    HeaderOption
+	Number    NumberOption
+	Inference TypeInferenceOption
 }
 
-func ReadSlice(arr [][]string, hasHeader bool) *DataFrame[any] {
-	seriess := make([]*Series[any], 0, len(arr))
-	for i, values := range arr {
-		if hasHeader {
-			header := values[0]
-			values = values[1:]
-			seriess = append(seriess, NewSeries[any](header, asType(values, determineType(values))...))
-		} else {
-			seriess = append(seriess, NewSeries[any](strconv.Itoa(i), asType(values, determineType(values))...))
-		}
+// ReadMap reads columns from a map, columns follow the iteration order of the map
+func ReadMap(data map[string][]string, option ...ReadSliceOption) *DataFrame[any] {
+	df, err := TryReadMap(data, option...)
+	if err != nil {
+		panic(err)
 	}
-	return NewDataFrame(seriess...)
+	return df
 }
 
-func determineType(arr []string) reflect.Kind {
-	var (
-		hasBool, hasFloat, hasInt, hasOthers bool
-	)
-	for _, val := range arr {
-		if val == "" {
-			continue
-		}
-		if val == "true" || val == "True" || val == "false" || val == "False" {
-			hasBool = true
-			continue
-		}
-		if _, err := strconv.ParseInt(val, 10, 64); err == nil {
-			hasInt = true
-			continue
-		}
-		if val == "NaN" {
-			hasFloat = true
-			continue
-		}
-		if _, err := strconv.ParseFloat(val, 64); err == nil {
-			hasFloat = true
-			continue
-		}
-
-		hasOthers = true
-		break // fast break on string
+// TryReadMap is like ReadMap but returns error instead of panic
+func TryReadMap(data map[string][]string, option ...ReadSliceOption) (*DataFrame[any], error) {
+	opt := firstReadSliceOption(option)
+	if err := opt.Number.check(); err != nil {
+		return nil, err
 	}
-
-	// mixed dtype, each value has its own go type
-	if hasOthers {
-		return reflect.Interface
+	header := make([]string, 0, len(data))
+	for name := range data {
+		header = append(header, name)
 	}
 
-	// float64 if has float values or has both float and int values and has not bool
-	if hasFloat && !hasBool {
-		return reflect.Float64
+	columns := make([][]string, 0, len(data))
+	for _, name := range header {
+		columns = append(columns, data[name])
 	}
+	return readColumns(opt.parser(), opt.Header.normalize(header), columns, make([]reflect.Kind, len(header)), 0, 0)
+}
 
-	// int64 if has only int values
-	if hasInt && !hasBool {
-		return reflect.Int64
+// ReadSlice reads columns from a slice, each slice is a column whose first value is the name if hasHeader is true
+func ReadSlice(arr [][]string, hasHeader bool, option ...ReadSliceOption) *DataFrame[any] {
+	df, err := TryReadSlice(arr, hasHeader, option...)
+	if err != nil {
+		panic(err)
 	}
+	return df
+}
 
-	// bool if has only bool values
-	if hasBool && !hasFloat && !hasInt {
-		return reflect.Bool
+// TryReadSlice is like ReadSlice but returns error instead of panic
+func TryReadSlice(arr [][]string, hasHeader bool, option ...ReadSliceOption) (*DataFrame[any], error) {
+	opt := firstReadSliceOption(option)
+	if err := opt.Number.check(); err != nil {
+		return nil, err
 	}
-
-	// otherwise, default interface
-	return reflect.Interface
+	header, data := splitSlice(arr, hasHeader)
+	return readColumns(opt.parser(), opt.Header.normalize(header), data, make([]reflect.Kind, len(header)), 0, 0)
 }
 
-func asType(arr []string, dtype reflect.Kind) []any {
-	switch dtype {
-	case reflect.Float64:
-		return asFloat64(arr)
-	case reflect.Int64:
-		return asInt64(arr)
-	case reflect.Bool:
-		return asBool(arr)
-	case reflect.Interface:
-		return asInterface(arr)
-	default:
-		panic("Unsupported type")
+// ReadSliceTyped is like ReadSlice but stores each column in its inferred type without boxing,
+// see Frame.Schema for the inferred schema
+func ReadSliceTyped(arr [][]string, hasHeader bool, option ...ReadSliceOption) *Frame {
+	f, err := TryReadSliceTyped(arr, hasHeader, option...)
+	if err != nil {
+		panic(err)
 	}
+	return f
 }
 
-func asFloat64(arr []string) []any {
-	values := make([]any, 0, len(arr))
-
-	for _, val := range arr {
-		switch val {
-		case "", "NaN", "None", "null":
-			values = append(values, math.NaN())
-		case "Inf", "inf":
-			values = append(values, math.Inf(1))
-		case "-Inf", "-inf":
-			values = append(values, math.Inf(-1))
-		default:
-			if v, err := strconv.ParseFloat(val, 64); err != nil {
-				panic(err)
-			} else {
-				values = append(values, v)
-			}
-		}
+// TryReadSliceTyped is like ReadSliceTyped but returns error instead of panic
+func TryReadSliceTyped(arr [][]string, hasHeader bool, option ...ReadSliceOption) (*Frame, error) {
+	opt := firstReadSliceOption(option)
+	if err := opt.Number.check(); err != nil {
+		return nil, err
 	}
+	header, data := splitSlice(arr, hasHeader)
+	return readTypedColumns(opt.parser(), opt.Header.normalize(header), data, make([]reflect.Kind, len(header)), 0, 0)
+}
 
-	return values
+// InferSchema returns the schema which ReadSlice and ReadSliceTyped infer from given columns without converting them
+func InferSchema(arr [][]string, hasHeader bool, option ...ReadSliceOption) Schema {
+	opt := firstReadSliceOption(option)
+	parser := opt.parser()
+	header, data := splitSlice(arr, hasHeader)
+	header = opt.Header.normalize(header)
+	schema := make(Schema, 0, len(header))
+	for i, values := range data {
+		schema = append(schema, Field{Name: header[i], DType: parser.determineType(values), Nullable: parser.hasNull(values)})
+	}
+	return schema
 }
 
-func asInt64(arr []string) []any {
-	values := make([]any, 0, len(arr))
-	for _, val := range arr {
-		if val == "" {
-			values = append(values, nil)
-		} else if v, err := strconv.ParseInt(val, 10, 64); err == nil {
-			values = append(values, v)
+func splitSlice(arr [][]string, hasHeader bool) (header []string, data [][]string) {
+	header = make([]string, 0, len(arr))
+	data = make([][]string, 0, len(arr))
+	for i, values := range arr {
+		if hasHeader {
+			header = append(header, values[0])
+			values = values[1:]
 		} else {
-			panic(err)
+			header = append(header, strconv.Itoa(i))
 		}
+		data = append(data, values)
 	}
+	return header, data
+}
+
+func (o ReadSliceOption) parser() valueParser {
+	return newValueParser(o.Number, o.Inference)
+}
 
-	return values
+func firstReadSliceOption(option []ReadSliceOption) ReadSliceOption {
+	if len(option) == 0 {
+		return ReadSliceOption{}
+	}
+	return option[0]
 }
 
-func asBool(arr []string) []any {
-	values := make([]any, 0, len(arr))
-	for _, val := range arr {
-		switch val {
-		case "True", "true":
-			values = append(values, true)
-		case "False", "false":
-			values = append(values, false)
-		case "":
-			values = append(values, nil)
-		default:
-			panic("not a bool value: " + val)
+// readColumns determines types of columns and converts them concurrently in given number of workers.
+// dtypes of reflect.Invalid are determined by values and written back,
+// rowOffset is added to the row of conversion errors.
+func readColumns(parser valueParser, header []string, data [][]string, dtypes []reflect.Kind, rowOffset int, workers int) (*DataFrame[any], error) {
+	seriess := make([]*Series[any], len(header))
+	err := convertColumns(parser, data, dtypes, rowOffset, workers, func(i int, dtype reflect.Kind) error {
+		elements, err := parser.asType(header[i], data[i], dtype)
+		if err == nil {
+			seriess[i] = NewSeries(header[i], elements...)
 		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return TryNewDataFrame(seriess...)
+}
 
-	return values
+// readTypedColumns is like readColumns but stores each column in its own type without boxing
+func readTypedColumns(parser valueParser, header []string, data [][]string, dtypes []reflect.Kind, rowOffset int, workers int) (*Frame, error) {
+	columns := make([]Column, len(header))
+	err := convertColumns(parser, data, dtypes, rowOffset, workers, func(i int, dtype reflect.Kind) (err error) {
+		columns[i], err = parser.asColumn(header[i], data[i], dtype)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return TryNewFrame(columns...)
 }
 
-func asInterface(arr []string) []any {
-	values := make([]any, 0, len(arr))
-	for _, val := range arr {
-		if val == "" || val == "NaN" {
-			values = append(values, math.NaN())
-			continue
+// convertColumns determines dtypes of reflect.Invalid and calls convert for each column concurrently.
+// A column inferred from samples is inferred again from all values if convert fails.
+func convertColumns(parser valueParser, data [][]string, dtypes []reflect.Kind, rowOffset int, workers int, convert func(i int, dtype reflect.Kind) error) error {
+	return parallel(len(data), workers, func(i int) error {
+		inferred := dtypes[i] == reflect.Invalid
+		if inferred {
+			dtypes[i] = parser.determineType(data[i])
 		}
-		if val == "true" || val == "True" {
-			values = append(values, true)
-			continue
+		err := convert(i, dtypes[i])
+		if err != nil && inferred && parser.sampleRows > 0 && parser.sampleRows < len(data[i]) {
+			dtypes[i] = parser.inferType(data[i])
+			err = convert(i, dtypes[i])
 		}
-		if val == "false" || val == "False" {
-			values = append(values, false)
-			continue
-		}
-		if val, err := strconv.ParseInt(val, 10, 64); err == nil {
-			values = append(values, val)
-			continue
-		}
-		if val, err := strconv.ParseFloat(val, 64); err == nil {
-			values = append(values, val)
-			continue
+		if err != nil {
+			var e *Error
+			if errors.As(err, &e) && e.Row >= 0 {
+				e.Row += rowOffset
+			}
+			return err
 		}
+		return nil
+	})
+}
 
-		// default is string
-		values = append(values, val)
+func defaultHeader(n int) []string {
+	header := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header = append(header, strconv.Itoa(i))
 	}
-	return values
+	return header
 }
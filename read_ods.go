@@ -0,0 +1,374 @@
+package pandat
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func ReadOdsPath(filepath string, option ReadXlsxOption) (*DataFrame[any], error) {
+	r, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadOds(r, option)
+}
+
+// ReadOds reads a sheet of an OpenDocument spreadsheet like ReadXlsx.
+// FormulaText reads formulas in OpenFormula syntax like "=SUM([.A1:.A3])", FormulaEvaluate and Password are not supported.
+func ReadOds(r io.Reader, option ReadXlsxOption) (*DataFrame[any], error) {
+	var (
+		found bool
+		count int
+	)
+	sheets, err := readOds(r, option, func(index int, name string) bool {
+		count++
+		if found {
+			return false
+		}
+		found = (option.Sheet == "" && index == option.SheetIndex) || (option.Sheet != "" && name == option.Sheet)
+		return found
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sheets) == 0 {
+		switch {
+		case option.Sheet != "":
+			return nil, newError("ReadOds", -1, "", ErrNameNotFound, fmt.Errorf("sheet %q", option.Sheet))
+		case count > 0:
+			return nil, newError("ReadOds", -1, "", ErrIndexOutOfRange, fmt.Errorf("sheet %d", option.SheetIndex))
+		}
+		return NewDataFrame[any](), nil
+	}
+	return sheets[0].read(option)
+}
+
+func ReadOdsAllPath(filepath string, option ReadXlsxOption) (map[string]*DataFrame[any], error) {
+	r, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadOdsAll(r, option)
+}
+
+// ReadOdsAll reads every sheet into a dataframe by sheet name, Sheet and SheetIndex of option are ignored
+func ReadOdsAll(r io.Reader, option ReadXlsxOption) (map[string]*DataFrame[any], error) {
+	sheets, err := readOds(r, option, func(int, string) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	dfs := make(map[string]*DataFrame[any], len(sheets))
+	for _, sheet := range sheets {
+		if dfs[sheet.name], err = sheet.read(option); err != nil {
+			return nil, err
+		}
+	}
+	return dfs, nil
+}
+
+// odsSheet is the cells of a table in content.xml by their types, and merged ranges of the table
+type odsSheet struct {
+	name   string
+	rows   [][]any
+	merges []excelize.MergeCell
+}
+
+func (s *odsSheet) read(option ReadXlsxOption) (*DataFrame[any], error) {
+	if option.NativeTypes {
+		return readNativeRows(s.rows, s.merges, option)
+	}
+	rows := make([][]string, len(s.rows))
+	for i, row := range s.rows {
+		rows[i] = make([]string, len(row))
+		for j, val := range row {
+			rows[i][j] = formatNativeValue(val)
+		}
+	}
+	return readStringRows(rows, s.merges, option)
+}
+
+// readOds reads tables in content.xml accepted by want, the other tables are skipped
+func readOds(r io.Reader, option ReadXlsxOption, want func(index int, name string) bool) ([]*odsSheet, error) {
+	if option.Formula == FormulaEvaluate {
+		return nil, errors.New("pandat: formulas of ods can not be evaluated")
+	}
+	if err := option.Number.check(); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	content, err := z.Open("content.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var (
+		d      = xml.NewDecoder(content)
+		parser = newValueParser(option.Number, option.Inference)
+		sheets []*odsSheet
+		index  int
+	)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return sheets, nil
+		} else if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "table" {
+			continue
+		}
+		name := xmlAttr(start, "name")
+		if !want(index, name) {
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		} else {
+			sheet := &odsSheet{name: name}
+			if err := sheet.readTable(d, &parser, option.Formula); err != nil {
+				return nil, err
+			}
+			sheets = append(sheets, sheet)
+		}
+		index++
+	}
+}
+
+// readTable reads rows of a table until its end, repeated empty rows and cells are kept only if followed by values,
+// so that the repeated empty rows and columns to the end of the sheet are not expanded
+func (s *odsSheet) readTable(d *xml.Decoder, parser *valueParser, formula FormulaAction) error {
+	emptyRows := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "table" {
+				return nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "table-row":
+				repeat := odsRepeat(t, "number-rows-repeated")
+				row, err := s.readRow(d, len(s.rows)+emptyRows, parser, formula)
+				if err != nil {
+					return err
+				}
+				if len(row) == 0 {
+					emptyRows += repeat
+					continue
+				}
+				for ; emptyRows > 0; emptyRows-- {
+					s.rows = append(s.rows, nil)
+				}
+				s.rows = append(s.rows, row)
+				for i := 1; i < repeat; i++ {
+					s.rows = append(s.rows, append([]any(nil), row...))
+				}
+			case "table-column", "shapes", "named-expressions":
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// readRow reads cells of the nth row until the end of the row
+func (s *odsSheet) readRow(d *xml.Decoder, nrow int, parser *valueParser, formula FormulaAction) ([]any, error) {
+	var (
+		row        []any
+		emptyCells int
+	)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "table-row" {
+				return row, nil
+			}
+		case xml.StartElement:
+			if t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell" {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			repeat := odsRepeat(t, "number-columns-repeated")
+			col := len(row) + emptyCells
+			if cols, rows := odsRepeat(t, "number-columns-spanned"), odsRepeat(t, "number-rows-spanned"); cols > 1 || rows > 1 {
+				from, _ := excelize.CoordinatesToCellName(col+1, nrow+1)
+				to, _ := excelize.CoordinatesToCellName(col+cols, nrow+rows)
+				s.merges = append(s.merges, excelize.MergeCell{from + ":" + to})
+			}
+			val, err := odsCellValue(d, t, formula)
+			if err != nil {
+				cellName, _ := excelize.CoordinatesToCellName(col+1, nrow+1)
+				return nil, newError("ReadOds", nrow, cellName, ErrConversion, err)
+			}
+			if v, ok := val.(string); ok && parser.isNull(v) {
+				val = nil
+			}
+			if val == nil {
+				emptyCells += repeat
+				continue
+			}
+			for ; emptyCells > 0; emptyCells-- {
+				row = append(row, nil)
+			}
+			for i := 0; i < repeat; i++ {
+				row = append(row, val)
+			}
+		}
+	}
+}
+
+// odsCellValue reads a cell until its end and returns the value by office:value-type
+func odsCellValue(d *xml.Decoder, cell xml.StartElement, formula FormulaAction) (any, error) {
+	text, err := readOdsText(d, cell.Name)
+	if err != nil {
+		return nil, err
+	}
+	if f := xmlAttr(cell, "formula"); f != "" && formula == FormulaText {
+		return "=" + strings.TrimPrefix(strings.TrimPrefix(f, "of:"), "="), nil
+	}
+
+	switch xmlAttr(cell, "value-type") {
+	case "float", "percentage", "currency":
+		return strconv.ParseFloat(xmlAttr(cell, "value"), 64)
+	case "date":
+		return parseOdsDate(xmlAttr(cell, "date-value"))
+	case "time":
+		duration, err := parseOdsDuration(xmlAttr(cell, "time-value"))
+		if err != nil {
+			return nil, err
+		}
+		// times are on the epoch of Excel like times read from xlsx
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).Add(duration), nil
+	case "boolean":
+		return strconv.ParseBool(xmlAttr(cell, "boolean-value"))
+	case "string":
+		if v := xmlAttr(cell, "string-value"); v != "" {
+			return v, nil
+		}
+	}
+	if text == "" {
+		return nil, nil
+	}
+	return text, nil
+}
+
+// readOdsText returns the text of paragraphs until the end of element, paragraphs are separated by line breaks,
+// text:s, text:tab and text:line-break are expanded and annotations are skipped
+func readOdsText(d *xml.Decoder, element xml.Name) (string, error) {
+	var (
+		b          strings.Builder
+		paragraphs int
+	)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			if paragraphs > 0 {
+				b.Write(t)
+			}
+		case xml.EndElement:
+			if t.Name == element {
+				return b.String(), nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p", "h":
+				if paragraphs > 0 {
+					b.WriteByte('\n')
+				}
+				paragraphs++
+			case "s":
+				n := odsRepeat(t, "c")
+				b.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				b.WriteByte('\t')
+			case "line-break":
+				b.WriteByte('\n')
+			case "annotation":
+				if err := d.Skip(); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+}
+
+// odsRepeat returns the count of an attribute like table:number-columns-repeated, 1 by default
+func odsRepeat(start xml.StartElement, name string) int {
+	n, err := strconv.Atoi(xmlAttr(start, name))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// parseOdsDate parses a date like "2024-01-02", "2024-01-02T10:30:00" or one with fractional seconds and a zone
+func parseOdsDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05.999999999", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// parseOdsDuration parses a duration of ISO 8601 like "PT10H30M00S" or "PT10H30M00.5S"
+func parseOdsDuration(s string) (time.Duration, error) {
+	if !strings.HasPrefix(s, "PT") {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	rest := s[2:]
+	var duration time.Duration
+	for _, unit := range []struct {
+		suffix string
+		unit   time.Duration
+	}{{"H", time.Hour}, {"M", time.Minute}, {"S", time.Second}} {
+		i := strings.Index(rest, unit.suffix)
+		if i < 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		duration += time.Duration(v * float64(unit.unit))
+		rest = rest[i+1:]
+	}
+	if rest != "" {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return duration, nil
+}
@@ -0,0 +1,158 @@
+package pandat
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testOds returns a spreadsheet of given tables like LibreOffice saves, with the namespaces declared
+func testOds(tables string) []byte {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	f, _ := z.Create("content.xml")
+	_, _ = f.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+ xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+ xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0">
+<office:body><office:spreadsheet>` + tables + `</office:spreadsheet></office:body></office:document-content>`))
+	_ = z.Close()
+	return buf.Bytes()
+}
+
+func TestReadOds(t *testing.T) {
+	data := testOds(`<table:table table:name="data">
+<table:table-column table:number-columns-repeated="1024"/>
+<table:table-row><table:table-cell office:value-type="string"><text:p>name</text:p></table:table-cell>` +
+		`<table:table-cell office:value-type="string"><text:p>amount</text:p></table:table-cell>` +
+		`<table:table-cell office:value-type="string"><text:p>day</text:p></table:table-cell>` +
+		`<table:table-cell office:value-type="string"><text:p>ok</text:p></table:table-cell>` +
+		`<table:table-cell table:number-columns-repeated="1020"/></table:table-row>
+<table:table-row table:number-rows-repeated="2"><table:table-cell office:value-type="string"><text:p>a<text:s text:c="2"/>b</text:p>` +
+		`<office:annotation><text:p>note</text:p></office:annotation></table:table-cell>` +
+		`<table:table-cell office:value-type="float" office:value="1.5" calcext:value-type="float"><text:p>1.50</text:p></table:table-cell>` +
+		`<table:table-cell office:value-type="date" office:date-value="2024-01-02"><text:p>01/02/24</text:p></table:table-cell>` +
+		`<table:table-cell office:value-type="boolean" office:boolean-value="true"><text:p>TRUE</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="3"><table:table-cell table:number-columns-repeated="4"/></table:table-row>
+<table:table-row><table:table-cell table:number-columns-spanned="2" table:number-rows-spanned="1" office:value-type="string"><text:p>N/A</text:p></table:table-cell>` +
+		`<table:covered-table-cell/>` +
+		`<table:table-cell office:value-type="time" office:time-value="PT10H30M00S"><text:p>10:30</text:p></table:table-cell>` +
+		`<table:table-cell table:formula="of:=1=1" office:value-type="boolean" office:boolean-value="false"><text:p>FALSE</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell office:value-type="percentage" office:value="0.25"><text:p>25%</text:p></table:table-cell>` +
+		`<table:table-cell table:number-columns-repeated="2" office:value-type="float" office:value="3"><text:p>3</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+<table:table table:name="other"><table:table-row><table:table-cell office:value-type="string"><text:p>x</text:p></table:table-cell></table:table-row></table:table>`)
+
+	df, err := ReadOds(bytes.NewReader(data), ReadXlsxOption{NativeTypes: true, Inference: TypeInferenceOption{NullValues: []string{"N/A"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nrows, ncols := df.Shape(); nrows != 7 || ncols != 4 {
+		t.Fatalf("unexpected shape: %d, %d", nrows, ncols)
+	}
+	// null values of mixed columns are NaN like ReadXlsx
+	if name := df.Get("name").Slice(); name[0] != "a  b" || name[1] != "a  b" || !isNull(name[5]) || name[6] != 0.25 {
+		t.Fatalf("unexpected name: %#v", name)
+	}
+	if amount := df.Get("amount").Slice(); amount[1] != 1.5 || !math.IsNaN(amount[2].(float64)) || amount[6] != 3.0 {
+		t.Fatalf("unexpected amount: %v", amount)
+	}
+	day := df.Get("day").Slice()
+	if day[0] != time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) || day[5] != time.Date(1899, 12, 30, 10, 30, 0, 0, time.UTC) {
+		t.Fatalf("unexpected day: %v", day)
+	}
+	if ok := df.Get("ok").Slice(); ok[0] != true || ok[5] != false || ok[6] != nil {
+		t.Fatalf("unexpected ok: %v", ok)
+	}
+
+	df, err = ReadOds(bytes.NewReader(data), ReadXlsxOption{Range: "C1:D7", Formula: FormulaText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok := df.Get("ok").Slice(); ok[5] != "=1=1" {
+		t.Fatalf("unexpected ok: %v", ok)
+	}
+
+	df, err = ReadOds(bytes.NewReader(data), ReadXlsxOption{FillMerged: true, HeaderRow: 6, NoHeader: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := df.Val(0, "0"), df.Val(0, "1"); a != "N/A" || b != "N/A" {
+		t.Fatalf("unexpected merged cells: %v, %v", a, b)
+	}
+
+	dfs, err := ReadOdsAll(bytes.NewReader(data), ReadXlsxOption{NoHeader: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dfs) != 2 || dfs["other"].Val(0, "0") != "x" {
+		t.Fatalf("unexpected sheets: %v", dfs)
+	}
+	if _, err := ReadOds(bytes.NewReader(data), ReadXlsxOption{Sheet: "missing"}); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("expected ErrNameNotFound, got %v", err)
+	}
+	if _, err := ReadOds(bytes.NewReader(data), ReadXlsxOption{SheetIndex: 9}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func TestOdsWriter(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	df := NewDataFrame(
+		NewSeries[any]("id", int64(1), int64(1), int64(1), int64(2)),
+		NewSeries[any]("name", "x", "x", "x", " a  b\tc\n"),
+		NewSeries[any]("amount", 1.5, 1.5, 1.5, math.NaN()),
+		NewSeries[any]("day", day, day, day, day.Add(90*time.Minute)),
+		NewSeries[any]("ok", true, true, true, nil),
+	)
+	w := NewOdsWriter()
+	if err := w.Write(df, WriteOdsOption{Sheet: "data"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(NewDataFrame(NewSeries[any]("a", "1", "1")), WriteOdsOption{Sheet: "dup", Index: true, IndexLabel: "no"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(df, WriteOdsOption{Sheet: "data"}); err == nil {
+		t.Fatalf("expected error of existing sheet")
+	}
+	if !strings.Contains(string(w.sheets[0].xml), `table:number-rows-repeated="3"`) {
+		t.Fatalf("expected repeated rows: %s", w.sheets[0].xml)
+	}
+	if !strings.Contains(string(w.sheets[1].xml), `table:number-columns-repeated="2"`) {
+		t.Fatalf("expected repeated cells: %s", w.sheets[1].xml)
+	}
+
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes()[30:], []byte("mimetype"+odsMimeType)) {
+		t.Fatalf("expected mimetype first and stored")
+	}
+	got, err := ReadOds(bytes.NewReader(buf.Bytes()), ReadXlsxOption{NativeTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Get("id").Slice(), []any{int64(1), int64(1), int64(1), int64(2)}) ||
+		!reflect.DeepEqual(got.Get("name").Slice(), df.Get("name").Slice()) ||
+		!reflect.DeepEqual(got.Get("day").Slice(), df.Get("day").Slice()) ||
+		!reflect.DeepEqual(got.Get("ok").Slice(), []any{true, true, true, nil}) {
+		t.Fatalf("unexpected dataframe: %v", got)
+	}
+	if amount := got.Get("amount").Slice(); amount[0] != 1.5 || !math.IsNaN(amount[3].(float64)) {
+		t.Fatalf("unexpected amount: %v", amount)
+	}
+	dup, err := ReadOds(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Sheet: "dup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dup.Names(), []string{"no", "a"}) || dup.Val(1, "a") != int64(1) {
+		t.Fatalf("unexpected dup: %v", dup)
+	}
+}
@@ -2,10 +2,12 @@ package pandat
 
 import (
 	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/schema"
 	"github.com/xitongsys/parquet-go/source"
 	"runtime"
+	"time"
 )
 
 func ReadParquetPath(filepath string) (*DataFrame[any], error) {
@@ -31,6 +33,9 @@ func ReadParquet(f source.ParquetFile) (*DataFrame[any], error) {
 		if err != nil {
 			return nil, err
 		}
+		if element := pr.SchemaHandler.SchemaElements[i+1]; element.ConvertedType != nil {
+			convertParquetValues(values, *element.ConvertedType)
+		}
 
 		seriess = append(seriess, NewSeries(names[i], values...))
 	}
@@ -47,3 +52,30 @@ func parquetColumnNames(schema *schema.SchemaHandler) []string {
 	}
 	return names
 }
+
+// convertParquetValues converts values of unsigned integer and timestamp columns from their physical types,
+// values of other converted types are kept
+func convertParquetValues(values []any, convertedType parquet.ConvertedType) {
+	for i, val := range values {
+		switch v := val.(type) {
+		case int32:
+			switch convertedType {
+			case parquet.ConvertedType_UINT_8:
+				values[i] = uint8(v)
+			case parquet.ConvertedType_UINT_16:
+				values[i] = uint16(v)
+			case parquet.ConvertedType_UINT_32:
+				values[i] = uint32(v)
+			}
+		case int64:
+			switch convertedType {
+			case parquet.ConvertedType_UINT_64:
+				values[i] = uint64(v)
+			case parquet.ConvertedType_TIMESTAMP_MILLIS:
+				values[i] = time.UnixMilli(v).UTC()
+			case parquet.ConvertedType_TIMESTAMP_MICROS:
+				values[i] = time.UnixMicro(v).UTC()
+			}
+		}
+	}
+}
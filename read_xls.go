@@ -0,0 +1,602 @@
+package pandat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/richardlehane/mscfb"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"math"
+	"os"
+	"unicode/utf16"
+)
+
+// record types of BIFF8 used by ReadXls
+const (
+	xlsFormula     = 0x0006
+	xlsEOF         = 0x000A
+	xlsDateMode    = 0x0022
+	xlsFilePass    = 0x002F
+	xlsContinue    = 0x003C
+	xlsBoundSheet  = 0x0085
+	xlsMulRK       = 0x00BD
+	xlsXF          = 0x00E0
+	xlsMergedCells = 0x00E5
+	xlsSST         = 0x00FC
+	xlsLabelSST    = 0x00FD
+	xlsNumber      = 0x0203
+	xlsLabel       = 0x0204
+	xlsBoolErr     = 0x0205
+	xlsString      = 0x0207
+	xlsRK          = 0x027E
+	xlsFormat      = 0x041E
+	xlsBOF         = 0x0809
+)
+
+func ReadXlsPath(filepath string, option ReadXlsxOption) (*DataFrame[any], error) {
+	r, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadXls(r, option)
+}
+
+// ReadXls reads a sheet of a legacy Excel 97-2003 workbook (BIFF8) like ReadXlsx.
+// Formulas are read as their cached values, and encrypted workbooks are not supported.
+func ReadXls(r io.Reader, option ReadXlsxOption) (*DataFrame[any], error) {
+	wb, err := openXls(r, option)
+	if err != nil {
+		return nil, err
+	}
+	if len(wb.sheets) == 0 {
+		return NewDataFrame[any](), nil
+	}
+
+	if option.Sheet == "" {
+		if option.SheetIndex < 0 || option.SheetIndex >= len(wb.sheets) {
+			return nil, newError("ReadXls", -1, "", ErrIndexOutOfRange, fmt.Errorf("sheet %d", option.SheetIndex))
+		}
+		return wb.readSheet(wb.sheets[option.SheetIndex])
+	}
+	for _, sheet := range wb.sheets {
+		if sheet.name == option.Sheet {
+			return wb.readSheet(sheet)
+		}
+	}
+	return nil, newError("ReadXls", -1, "", ErrNameNotFound, fmt.Errorf("sheet %q", option.Sheet))
+}
+
+func ReadXlsAllPath(filepath string, option ReadXlsxOption) (map[string]*DataFrame[any], error) {
+	r, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadXlsAll(r, option)
+}
+
+// ReadXlsAll reads every sheet into a dataframe by sheet name, Sheet and SheetIndex of option are ignored
+func ReadXlsAll(r io.Reader, option ReadXlsxOption) (map[string]*DataFrame[any], error) {
+	wb, err := openXls(r, option)
+	if err != nil {
+		return nil, err
+	}
+	dfs := make(map[string]*DataFrame[any], len(wb.sheets))
+	for _, sheet := range wb.sheets {
+		if dfs[sheet.name], err = wb.readSheet(sheet); err != nil {
+			return nil, err
+		}
+	}
+	return dfs, nil
+}
+
+// xlsWorkbook is the workbook stream of a BIFF8 file with records of the workbook globals parsed
+type xlsWorkbook struct {
+	data     []byte
+	sheets   []xlsSheet
+	shared   []string
+	date1904 bool
+	// dates are whether each cell format (XF) is a date or time format
+	dates  []bool
+	option ReadXlsxOption
+}
+
+// xlsSheet is a worksheet and the offset of its BOF record in the workbook stream
+type xlsSheet struct {
+	name   string
+	offset int
+}
+
+func openXls(r io.Reader, option ReadXlsxOption) (*xlsWorkbook, error) {
+	if option.Formula != FormulaValue {
+		return nil, errors.New("pandat: formulas of xls can only be read as cached values")
+	}
+	if err := option.Number.check(); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	wb := &xlsWorkbook{option: option}
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if entry.Name == "Book" {
+			return nil, errors.New("pandat: only BIFF8 xls of Excel 97 and later is supported")
+		}
+		if entry.Name == "Workbook" && len(entry.Path) == 0 {
+			wb.data = make([]byte, entry.Size)
+			if _, err := io.ReadFull(entry, wb.data); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if wb.data == nil {
+		return nil, errors.New("pandat: no workbook stream in xls")
+	}
+	if err := wb.readGlobals(); err != nil {
+		return nil, err
+	}
+	return wb, nil
+}
+
+// readGlobals reads sheets, shared strings, the date system and cell formats of the workbook
+func (wb *xlsWorkbook) readGlobals() error {
+	var (
+		formats = make(map[int]string)
+		xfs     []int
+	)
+	records := &xlsRecords{data: wb.data}
+	if err := records.bof(); err != nil {
+		return err
+	}
+	for {
+		id, body, err := records.next()
+		if err != nil {
+			return err
+		}
+		switch id {
+		case xlsEOF:
+			wb.dates = make([]bool, len(xfs))
+			for i, format := range xfs {
+				if code, ok := formats[format]; ok {
+					wb.dates[i] = isDateFormat(code)
+				} else {
+					wb.dates[i] = isBuiltInDateFormat(format)
+				}
+			}
+			return nil
+		case xlsFilePass:
+			return errors.New("pandat: encrypted xls is not supported")
+		case xlsDateMode:
+			wb.date1904 = len(body) >= 2 && binary.LittleEndian.Uint16(body) == 1
+		case xlsFormat:
+			if len(body) < 2 {
+				return errXlsRecord(id)
+			}
+			code, _, err := xlsUnicodeString(body[2:], 2)
+			if err != nil {
+				return err
+			}
+			formats[int(binary.LittleEndian.Uint16(body))] = code
+		case xlsXF:
+			if len(body) < 4 {
+				return errXlsRecord(id)
+			}
+			xfs = append(xfs, int(binary.LittleEndian.Uint16(body[2:])))
+		case xlsBoundSheet:
+			if len(body) < 8 {
+				return errXlsRecord(id)
+			}
+			name, _, err := xlsUnicodeString(body[6:], 1)
+			if err != nil {
+				return err
+			}
+			// only worksheets, not chart sheets or macro sheets
+			if body[5] == 0 {
+				wb.sheets = append(wb.sheets, xlsSheet{name: name, offset: int(binary.LittleEndian.Uint32(body))})
+			}
+		case xlsSST:
+			fragments := [][]byte{body}
+			for records.peek() == xlsContinue {
+				_, body, _ := records.next()
+				fragments = append(fragments, body)
+			}
+			if wb.shared, err = readXlsSST(fragments); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (wb *xlsWorkbook) readSheet(sheet xlsSheet) (*DataFrame[any], error) {
+	records, merges, err := wb.readCells(sheet)
+	if err != nil {
+		return nil, err
+	}
+	if wb.option.NativeTypes {
+		return readNativeRows(records, merges, wb.option)
+	}
+
+	rows := make([][]string, len(records))
+	for i, row := range records {
+		rows[i] = make([]string, len(row))
+		for j, val := range row {
+			rows[i][j] = formatNativeValue(val)
+		}
+	}
+	return readStringRows(rows, merges, wb.option)
+}
+
+// readCells reads cells of a sheet by their types like xlsxWorkbook.nativeRows, and merged ranges of the sheet
+func (wb *xlsWorkbook) readCells(sheet xlsSheet) ([][]any, []excelize.MergeCell, error) {
+	if sheet.offset < 0 || sheet.offset >= len(wb.data) {
+		return nil, nil, fmt.Errorf("pandat: invalid offset of sheet %s", sheet.name)
+	}
+	var (
+		parser  = newValueParser(wb.option.Number, wb.option.Inference)
+		records [][]any
+		merges  []excelize.MergeCell
+		// the cell of a formula whose string value is in the next STRING record
+		stringRow, stringCol = -1, -1
+	)
+	set := func(row, col int, val any) {
+		if s, ok := val.(string); ok && parser.isNull(s) {
+			val = nil
+		}
+		for len(records) <= row {
+			records = append(records, nil)
+		}
+		for len(records[row]) <= col {
+			records[row] = append(records[row], nil)
+		}
+		records[row][col] = val
+	}
+
+	r := &xlsRecords{data: wb.data, pos: sheet.offset}
+	if err := r.bof(); err != nil {
+		return nil, nil, err
+	}
+	for {
+		id, body, err := r.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if id == xlsEOF {
+			return records, merges, nil
+		}
+		if (id == xlsLabelSST || id == xlsNumber || id == xlsLabel || id == xlsBoolErr || id == xlsRK || id == xlsFormula || id == xlsMulRK) && len(body) < 6 {
+			return nil, nil, errXlsRecord(id)
+		}
+		var row, col, xf int
+		if len(body) >= 6 {
+			row, col, xf = int(binary.LittleEndian.Uint16(body)), int(binary.LittleEndian.Uint16(body[2:])), int(binary.LittleEndian.Uint16(body[4:]))
+		}
+
+		switch id {
+		case xlsLabelSST:
+			if len(body) < 10 {
+				return nil, nil, errXlsRecord(id)
+			}
+			i := int(binary.LittleEndian.Uint32(body[6:]))
+			if i >= len(wb.shared) {
+				cellName, _ := excelize.CoordinatesToCellName(col+1, row+1)
+				return nil, nil, newError("ReadXls", row, cellName, ErrConversion, fmt.Errorf("invalid shared string index %d", i))
+			}
+			set(row, col, wb.shared[i])
+		case xlsLabel:
+			s, _, err := xlsUnicodeString(body[6:], 2)
+			if err != nil {
+				return nil, nil, err
+			}
+			set(row, col, s)
+		case xlsNumber:
+			if len(body) < 14 {
+				return nil, nil, errXlsRecord(id)
+			}
+			set(row, col, wb.number(math.Float64frombits(binary.LittleEndian.Uint64(body[6:])), xf))
+		case xlsRK:
+			if len(body) < 10 {
+				return nil, nil, errXlsRecord(id)
+			}
+			set(row, col, wb.number(xlsRKNumber(binary.LittleEndian.Uint32(body[6:])), xf))
+		case xlsMulRK:
+			// row, first column, 6 bytes of XF and RK for each column, last column
+			for i := 4; i+6 <= len(body)-2; i, col = i+6, col+1 {
+				xf := int(binary.LittleEndian.Uint16(body[i:]))
+				set(row, col, wb.number(xlsRKNumber(binary.LittleEndian.Uint32(body[i+2:])), xf))
+			}
+		case xlsBoolErr:
+			if len(body) < 8 {
+				return nil, nil, errXlsRecord(id)
+			}
+			if body[7] == 0 {
+				set(row, col, body[6] != 0)
+			} else {
+				// error values like #N/A are null
+				set(row, col, nil)
+			}
+		case xlsFormula:
+			if len(body) < 14 {
+				return nil, nil, errXlsRecord(id)
+			}
+			result := body[6:14]
+			if binary.LittleEndian.Uint16(result[6:]) != 0xFFFF {
+				set(row, col, wb.number(math.Float64frombits(binary.LittleEndian.Uint64(result)), xf))
+				break
+			}
+			switch result[0] {
+			case 0:
+				stringRow, stringCol = row, col
+			case 1:
+				set(row, col, result[2] != 0)
+			default:
+				// errors and empty strings
+				set(row, col, nil)
+			}
+		case xlsString:
+			if stringRow < 0 {
+				break
+			}
+			s, _, err := xlsUnicodeString(body, 2)
+			if err != nil {
+				return nil, nil, err
+			}
+			set(stringRow, stringCol, s)
+			stringRow, stringCol = -1, -1
+		case xlsMergedCells:
+			if len(body) < 2 {
+				return nil, nil, errXlsRecord(id)
+			}
+			n := int(binary.LittleEndian.Uint16(body))
+			for i := 0; i < n && 2+i*8+8 <= len(body); i++ {
+				ref := body[2+i*8:]
+				from, _ := excelize.CoordinatesToCellName(int(binary.LittleEndian.Uint16(ref[4:]))+1, int(binary.LittleEndian.Uint16(ref))+1)
+				to, _ := excelize.CoordinatesToCellName(int(binary.LittleEndian.Uint16(ref[6:]))+1, int(binary.LittleEndian.Uint16(ref[2:]))+1)
+				merges = append(merges, excelize.MergeCell{from + ":" + to})
+			}
+		}
+	}
+}
+
+// number returns a number or a date by the format of the cell, dates are serial numbers if RawCellValue of option is true
+func (wb *xlsWorkbook) number(v float64, xf int) any {
+	if wb.option.RawCellValue && !wb.option.NativeTypes {
+		return v
+	}
+	if xf < len(wb.dates) && wb.dates[xf] {
+		if t, err := excelize.ExcelDateToTime(v, wb.date1904); err == nil {
+			return t
+		}
+	}
+	return v
+}
+
+// xlsRecords iterates records of a BIFF8 stream from pos
+type xlsRecords struct {
+	data []byte
+	pos  int
+}
+
+// next returns the type and the body of the next record
+func (r *xlsRecords) next() (uint16, []byte, error) {
+	if r.pos+4 > len(r.data) {
+		return 0, nil, errors.New("pandat: unexpected end of xls workbook stream")
+	}
+	id := binary.LittleEndian.Uint16(r.data[r.pos:])
+	size := int(binary.LittleEndian.Uint16(r.data[r.pos+2:]))
+	if r.pos+4+size > len(r.data) {
+		return 0, nil, errors.New("pandat: unexpected end of xls workbook stream")
+	}
+	body := r.data[r.pos+4 : r.pos+4+size]
+	r.pos += 4 + size
+	return id, body, nil
+}
+
+// peek returns the type of the next record or 0 if there is no more record
+func (r *xlsRecords) peek() uint16 {
+	if r.pos+4 > len(r.data) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(r.data[r.pos:])
+}
+
+// bof reads the BOF record of a substream and checks it is of BIFF8
+func (r *xlsRecords) bof() error {
+	id, body, err := r.next()
+	if err != nil {
+		return err
+	}
+	if id != xlsBOF || len(body) < 2 || binary.LittleEndian.Uint16(body) != 0x0600 {
+		return errors.New("pandat: only BIFF8 xls of Excel 97 and later is supported")
+	}
+	return nil
+}
+
+func errXlsRecord(id uint16) error {
+	return fmt.Errorf("pandat: invalid xls record 0x%04X", id)
+}
+
+// xlsRKNumber decodes a RK number, which is an integer or the high 30 bits of a float64, and may be multiplied by 100
+func xlsRKNumber(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// xlsUnicodeString decodes a string of which the character count is of lenSize bytes,
+// and returns the string and the number of bytes read
+func xlsUnicodeString(b []byte, lenSize int) (string, int, error) {
+	if len(b) < lenSize+1 {
+		return "", 0, errors.New("pandat: invalid xls string")
+	}
+	var n int
+	if lenSize == 1 {
+		n = int(b[0])
+	} else {
+		n = int(binary.LittleEndian.Uint16(b))
+	}
+	flags := b[lenSize]
+	pos := lenSize + 1
+	var runs, ext int
+	if flags&0x08 != 0 {
+		if len(b) < pos+2 {
+			return "", 0, errors.New("pandat: invalid xls string")
+		}
+		runs = int(binary.LittleEndian.Uint16(b[pos:]))
+		pos += 2
+	}
+	if flags&0x04 != 0 {
+		if len(b) < pos+4 {
+			return "", 0, errors.New("pandat: invalid xls string")
+		}
+		ext = int(binary.LittleEndian.Uint32(b[pos:]))
+		pos += 4
+	}
+	size := n
+	if flags&0x01 != 0 {
+		size *= 2
+	}
+	if len(b) < pos+size {
+		return "", 0, errors.New("pandat: invalid xls string")
+	}
+	s := decodeXlsChars(b[pos:pos+size], flags&0x01 != 0)
+	return s, pos + size + runs*4 + ext, nil
+}
+
+// decodeXlsChars decodes UTF-16LE characters, or Latin-1 characters of which the high bytes are omitted
+func decodeXlsChars(b []byte, wide bool) string {
+	if !wide {
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	}
+	chars := make([]uint16, len(b)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(chars))
+}
+
+// readXlsSST reads shared strings from the SST record and its CONTINUE records,
+// characters of a string split into a CONTINUE record are preceded by a byte of flags
+func readXlsSST(fragments [][]byte) ([]string, error) {
+	r := &xlsFragments{fragments: fragments}
+	head, err := r.read(8)
+	if err != nil {
+		return nil, err
+	}
+	n := int(binary.LittleEndian.Uint32(head[4:]))
+	shared := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		s, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		shared = append(shared, s)
+	}
+	return shared, nil
+}
+
+// xlsFragments reads bytes across the body of a record and its CONTINUE records
+type xlsFragments struct {
+	fragments [][]byte
+	i, pos    int
+}
+
+// read returns the next n bytes, which may be continued in the next fragment
+func (r *xlsFragments) read(n int) ([]byte, error) {
+	var b []byte
+	for n > 0 {
+		if r.i >= len(r.fragments) {
+			return nil, errors.New("pandat: invalid xls shared strings")
+		}
+		fragment := r.fragments[r.i][r.pos:]
+		if len(fragment) == 0 {
+			r.i, r.pos = r.i+1, 0
+			continue
+		}
+		m := n
+		if m > len(fragment) {
+			m = len(fragment)
+		}
+		b = append(b, fragment[:m]...)
+		r.pos += m
+		n -= m
+	}
+	return b, nil
+}
+
+func (r *xlsFragments) readString() (string, error) {
+	head, err := r.read(3)
+	if err != nil {
+		return "", err
+	}
+	n, flags := int(binary.LittleEndian.Uint16(head)), head[2]
+	var runs, ext int
+	if flags&0x08 != 0 {
+		b, err := r.read(2)
+		if err != nil {
+			return "", err
+		}
+		runs = int(binary.LittleEndian.Uint16(b))
+	}
+	if flags&0x04 != 0 {
+		b, err := r.read(4)
+		if err != nil {
+			return "", err
+		}
+		ext = int(binary.LittleEndian.Uint32(b))
+	}
+
+	var s []byte
+	wide := flags&0x01 != 0
+	for n > 0 {
+		if r.i < len(r.fragments) && r.pos == len(r.fragments[r.i]) {
+			// characters continued in the next fragment are preceded by their own flags
+			r.i, r.pos = r.i+1, 0
+			b, err := r.read(1)
+			if err != nil {
+				return "", err
+			}
+			wide = b[0]&0x01 != 0
+		}
+		if r.i >= len(r.fragments) {
+			return "", errors.New("pandat: invalid xls shared strings")
+		}
+		width := 1
+		if wide {
+			width = 2
+		}
+		m := (len(r.fragments[r.i]) - r.pos) / width
+		if m > n {
+			m = n
+		}
+		if m == 0 {
+			return "", errors.New("pandat: invalid xls shared strings")
+		}
+		b, _ := r.read(m * width)
+		s = append(s, decodeXlsChars(b, wide)...)
+		n -= m
+	}
+	if _, err := r.read(runs*4 + ext); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
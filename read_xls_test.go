@@ -0,0 +1,231 @@
+package pandat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// xlsRecord returns a BIFF8 record
+func xlsRecord(id uint16, body ...[]byte) []byte {
+	data := bytes.Join(body, nil)
+	return append(xlsUint16(int(id), len(data)), data...)
+}
+
+func xlsUint16(v ...int) []byte {
+	b := make([]byte, len(v)*2)
+	for i, v := range v {
+		binary.LittleEndian.PutUint16(b[i*2:], uint16(v))
+	}
+	return b
+}
+
+func xlsUint32(v ...int) []byte {
+	b := make([]byte, len(v)*4)
+	for i, v := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], uint32(v))
+	}
+	return b
+}
+
+func xlsFloat(v float64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	return b
+}
+
+// xlsTestString returns a string with a 2 bytes character count, in UTF-16 if wide
+func xlsTestString(s string, wide bool) []byte {
+	if !wide {
+		return append(append(xlsUint16(len(s)), 0), s...)
+	}
+	chars := utf16.Encode([]rune(s))
+	b := append(xlsUint16(len(chars)), 1)
+	for _, c := range chars {
+		b = append(b, xlsUint16(int(c))...)
+	}
+	return b
+}
+
+func xlsCell(id uint16, row, col, xf int, value ...[]byte) []byte {
+	return xlsRecord(id, append([][]byte{xlsUint16(row, col, xf)}, value...)...)
+}
+
+// testXls builds a workbook of sheets "data" and "empty" in a compound file
+func testXls() []byte {
+	bof := func(dt int) []byte { return xlsRecord(xlsBOF, xlsUint16(0x0600, dt), make([]byte, 12)) }
+	eof := xlsRecord(xlsEOF)
+
+	// the fourth string "abc北京市" is split into a CONTINUE record in UTF-16
+	sst := xlsRecord(xlsSST, xlsUint32(5, 5),
+		xlsTestString("name", false), xlsTestString("amount", false), xlsTestString("day", false),
+		xlsUint16(6), []byte{0}, []byte("abc"))
+	sst = append(sst, xlsRecord(xlsContinue, []byte{1}, xlsUint16(0x5317, 0x4EAC, 0x5E02), xlsTestString("x", false))...)
+
+	xf := func(format int) []byte { return xlsRecord(xlsXF, xlsUint16(0, format), make([]byte, 16)) }
+	boundSheet := func(offset int, name string) []byte {
+		return xlsRecord(xlsBoundSheet, xlsUint32(offset), []byte{0, 0, byte(len(name)), 0}, []byte(name))
+	}
+	globals := func(offsets ...int) []byte {
+		return bytes.Join([][]byte{
+			bof(0x0005),
+			xlsRecord(xlsDateMode, xlsUint16(0)),
+			xlsRecord(xlsFormat, xlsUint16(164), xlsTestString("yyyy/mm/dd", false)),
+			xf(0), xf(14), xf(164), xf(2),
+			boundSheet(offsets[0], "data"),
+			boundSheet(offsets[1], "empty"),
+			sst,
+			eof,
+		}, nil)
+	}
+
+	data := bytes.Join([][]byte{
+		bof(0x0010),
+		// header of shared strings
+		xlsCell(xlsLabelSST, 0, 0, 0, xlsUint32(0)),
+		xlsCell(xlsLabelSST, 0, 1, 0, xlsUint32(1)),
+		xlsCell(xlsLabelSST, 0, 2, 0, xlsUint32(2)),
+		xlsCell(xlsLabel, 0, 3, 0, xlsTestString("ok", false)),
+		// row 1: shared string, RK integer, built-in date, bool
+		xlsCell(xlsLabelSST, 1, 0, 0, xlsUint32(3)),
+		xlsCell(xlsRK, 1, 1, 0, xlsUint32(12<<2|2)),
+		xlsCell(xlsNumber, 1, 2, 1, xlsFloat(45293)),
+		xlsCell(xlsBoolErr, 1, 3, 0, []byte{1, 0}),
+		// row 2: formula of string, MULRK of 1.5 and a custom date, formula of bool
+		xlsCell(xlsFormula, 2, 0, 0, []byte{0, 0, 0, 0, 0, 0, 0xFF, 0xFF}, make([]byte, 6)),
+		xlsRecord(xlsString, xlsTestString("北京", true)),
+		xlsRecord(xlsMulRK, xlsUint16(2, 1, 3), xlsUint32(150<<2|3),
+			xlsUint16(2), xlsUint32(45294<<2|2), xlsUint16(2)),
+		xlsCell(xlsFormula, 2, 3, 0, []byte{1, 0, 0, 0, 0, 0, 0xFF, 0xFF}, make([]byte, 6)),
+		// row 3: error value, formula of number
+		xlsCell(xlsBoolErr, 3, 0, 0, []byte{0x2A, 1}),
+		xlsCell(xlsFormula, 3, 1, 3, xlsFloat(2.25), make([]byte, 6)),
+		xlsRecord(xlsMergedCells, xlsUint16(1, 3, 4, 1, 2)),
+		eof,
+	}, nil)
+	empty := bytes.Join([][]byte{bof(0x0010), eof}, nil)
+
+	n := len(globals(0, 0))
+	workbook := bytes.Join([][]byte{globals(n, n+len(data)), data, empty}, nil)
+	return testCompoundFile("Workbook", workbook)
+}
+
+// testCompoundFile returns a compound file of one stream in 512 bytes sectors,
+// sector 0 is the FAT, sector 1 is the directory and the stream follows
+func testCompoundFile(name string, stream []byte) []byte {
+	const (
+		sectorSize = 512
+		endOfChain = 0xFFFFFFFE
+		free       = 0xFFFFFFFF
+	)
+	if len(stream) < 4096 {
+		// smaller streams are stored in the mini stream
+		stream = append(stream, make([]byte, 4096-len(stream))...)
+	}
+	sectors := (len(stream) + sectorSize - 1) / sectorSize
+
+	header := make([]byte, sectorSize)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	copy(header[24:], xlsUint16(0x003E, 3, 0xFFFE, 9, 6))
+	le := binary.LittleEndian
+	le.PutUint32(header[44:], 1)          // FAT sectors
+	le.PutUint32(header[48:], 1)          // first directory sector
+	le.PutUint32(header[56:], 4096)       // mini stream cutoff
+	le.PutUint32(header[60:], endOfChain) // first mini FAT sector
+	le.PutUint32(header[68:], endOfChain) // first DIFAT sector
+	le.PutUint32(header[76:], 0)
+	for i := 80; i < sectorSize; i += 4 {
+		le.PutUint32(header[i:], free)
+	}
+
+	fat := make([]byte, sectorSize)
+	for i := 0; i < sectorSize/4; i++ {
+		le.PutUint32(fat[i*4:], free)
+	}
+	le.PutUint32(fat, 0xFFFFFFFD)
+	le.PutUint32(fat[4:], endOfChain)
+	for i := 0; i < sectors; i++ {
+		next := uint32(i + 3)
+		if i == sectors-1 {
+			next = endOfChain
+		}
+		le.PutUint32(fat[(i+2)*4:], next)
+	}
+
+	dir := make([]byte, sectorSize)
+	entry := func(i int, name string, typ byte, child, start uint32, size int) {
+		e := dir[i*128:]
+		chars := utf16.Encode([]rune(name))
+		for j, c := range chars {
+			le.PutUint16(e[j*2:], c)
+		}
+		le.PutUint16(e[64:], uint16(len(chars)*2+2))
+		e[66], e[67] = typ, 1
+		le.PutUint32(e[68:], free)
+		le.PutUint32(e[72:], free)
+		le.PutUint32(e[76:], child)
+		le.PutUint32(e[116:], start)
+		le.PutUint32(e[120:], uint32(size))
+	}
+	entry(0, "Root Entry", 5, 1, endOfChain, 0)
+	entry(1, name, 2, free, 2, len(stream))
+	for i := 2; i < 4; i++ {
+		le.PutUint32(dir[i*128+68:], free)
+		le.PutUint32(dir[i*128+72:], free)
+		le.PutUint32(dir[i*128+76:], free)
+	}
+
+	data := bytes.Join([][]byte{header, fat, dir, stream}, nil)
+	return append(data, make([]byte, (sectorSize-len(stream)%sectorSize)%sectorSize)...)
+}
+
+func TestReadXls(t *testing.T) {
+	data := testXls()
+	df, err := ReadXls(bytes.NewReader(data), ReadXlsxOption{NativeTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := df.Names(); !reflect.DeepEqual(names, []string{"name", "amount", "day", "ok"}) {
+		t.Fatalf("unexpected names: %v", names)
+	}
+	if name := df.Get("name").Slice(); !reflect.DeepEqual(name, []any{"abc北京市", "北京", nil}) {
+		t.Fatalf("unexpected name: %#v", name)
+	}
+	if amount := df.Get("amount").Slice(); !reflect.DeepEqual(amount, []any{12.0, 1.5, 2.25}) {
+		t.Fatalf("unexpected amount: %v", amount)
+	}
+	day := df.Get("day").Slice()
+	if day[0] != time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) || day[1] != time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("unexpected day: %v", day)
+	}
+	if ok := df.Get("ok").Slice(); !reflect.DeepEqual(ok, []any{true, false, nil}) {
+		t.Fatalf("unexpected ok: %v", ok)
+	}
+
+	df, err = ReadXls(bytes.NewReader(data), ReadXlsxOption{FillMerged: true, Range: "B1:C4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount := df.Get("amount").Slice(); !reflect.DeepEqual(amount, []any{12.0, 1.5, 2.25}) {
+		t.Fatalf("unexpected amount: %v", amount)
+	}
+	if day := df.Get("day").Slice(); !reflect.DeepEqual(day, []any{"2024-01-02", "2024-01-03", "2.25"}) {
+		t.Fatalf("unexpected day: %v", day)
+	}
+
+	dfs, err := ReadXlsAll(bytes.NewReader(data), ReadXlsxOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dfs) != 2 || dfs["data"].NRows() != 3 || dfs["empty"].NCols() != 0 {
+		t.Fatalf("unexpected sheets: %v", dfs)
+	}
+	if _, err := ReadXls(bytes.NewReader(data), ReadXlsxOption{Sheet: "missing"}); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("expected ErrNameNotFound, got %v", err)
+	}
+}
@@ -1,19 +1,64 @@
 package pandat
 
 import (
+	"fmt"
 	"github.com/xuri/excelize/v2"
 	"io"
+	"math"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ReadXlsxOption struct {
+	// NoHeader names columns by their indexes, "0", "1" and so on
 	NoHeader     bool
 	Sheet        string
 	SheetIndex   int
 	Password     string
 	RawCellValue bool
+	// Range is an A1-style range to read, e.g. "B3:F200", "B:F" or "3:200", the whole sheet is read if empty
+	Range string
+	// HeaderRow is the index of the header row in the range, rows before the header are skipped
+	HeaderRow int
+	// SkipFooter is the number of rows to skip at the end of the range
+	SkipFooter int
+	// Header normalizes column names
+	Header HeaderOption
+	// Number describes how numbers are formatted
+	Number NumberOption
+	// Inference is the vocabulary of bool and null values
+	Inference TypeInferenceOption
+	// BadLines is the action for rows with more cells than the header,
+	// rows with less cells are always padded with empty values because trailing empty cells are not stored
+	BadLines BadLineAction
+	// OnBadLine is called with the row number and cells of each bad row if BadLines is BadLineWarn
+	OnBadLine func(line int, record []string)
+	// NativeTypes reads cells by their types in the workbook instead of formatted strings:
+	// numbers are float64, or int64 if all numbers of a column are integers, dates are time.Time,
+	// texts like "00123" are kept as strings and error cells are null. Number is not used.
+	NativeTypes bool
+	// Formula is how cells with formulas are read, the cached values by default
+	Formula FormulaAction
+	// FillMerged fills every cell of a merged range with the value of its top-left cell,
+	// otherwise only the top-left cell has the value
+	FillMerged bool
 }
 
+// FormulaAction is how cells with formulas are read
+type FormulaAction int
+
+const (
+	// FormulaValue reads the values cached by the application which saved the workbook
+	FormulaValue FormulaAction = iota
+	// FormulaText reads formulas as strings, e.g. "=SUM(A1:A3)"
+	FormulaText
+	// FormulaEvaluate evaluates formulas by excelize, for workbooks without cached values
+	FormulaEvaluate
+)
+
 func ReadXlsxPath(filepath string, option ReadXlsxOption) (*DataFrame[any], error) {
 	r, err := os.Open(filepath)
 	if err != nil {
@@ -23,43 +68,596 @@ func ReadXlsxPath(filepath string, option ReadXlsxOption) (*DataFrame[any], erro
 }
 
 func ReadXlsx(r io.Reader, option ReadXlsxOption) (*DataFrame[any], error) {
-	f, err := excelize.OpenReader(r, excelize.Options{
-		Password:     option.Password,
-		RawCellValue: option.RawCellValue,
-	})
+	wb, err := openXlsx(r, option)
 	if err != nil {
 		return nil, err
 	}
 
-	sheets := f.GetSheetList()
+	sheets := wb.file.GetSheetList()
 
 	if len(sheets) == 0 {
 		// empty excel
 		return NewDataFrame[any](), nil
 	}
 
-	var sheet string
-	if option.Sheet != "" {
-		sheet = option.Sheet
-	} else {
-		sheet = sheets[option.SheetIndex]
+	if option.Sheet == "" {
+		if option.SheetIndex < 0 || option.SheetIndex >= len(sheets) {
+			return nil, newError("ReadXlsx", -1, "", ErrIndexOutOfRange, fmt.Errorf("sheet %d", option.SheetIndex))
+		}
+		return wb.readSheet(sheets[option.SheetIndex])
+	}
+	for _, sheet := range sheets {
+		if strings.EqualFold(sheet, option.Sheet) {
+			return wb.readSheet(sheet)
+		}
+	}
+	return nil, newError("ReadXlsx", -1, "", ErrNameNotFound, fmt.Errorf("sheet %q", option.Sheet))
+}
+
+func ReadXlsxAllPath(filepath string, option ReadXlsxOption) (map[string]*DataFrame[any], error) {
+	r, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadXlsxAll(r, option)
+}
+
+// ReadXlsxAll reads every sheet into a dataframe by sheet name, Sheet and SheetIndex of option are ignored
+func ReadXlsxAll(r io.Reader, option ReadXlsxOption) (map[string]*DataFrame[any], error) {
+	wb, err := openXlsx(r, option)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := wb.file.GetSheetList()
+	dfs := make(map[string]*DataFrame[any], len(sheets))
+	for _, sheet := range sheets {
+		if dfs[sheet], err = wb.readSheet(sheet); err != nil {
+			return nil, err
+		}
+	}
+	return dfs, nil
+}
+
+// ReadXlsxTable reads an Excel table by its name,
+// Sheet, SheetIndex, Range, HeaderRow, SkipFooter and NoHeader of option are determined by the table
+func ReadXlsxTable(r io.Reader, tableName string, option ...ReadXlsxOption) (*DataFrame[any], error) {
+	wb, err := openXlsx(r, firstReadXlsxOption(option))
+	if err != nil {
+		return nil, err
+	}
+	table, err := findXlsxTable(wb.file, tableName)
+	if err != nil {
+		return nil, err
+	} else if table == nil {
+		return nil, newError("ReadXlsxTable", -1, "", ErrNameNotFound, fmt.Errorf("table %q", tableName))
+	}
+
+	wb.option.Sheet, wb.option.Range = table.sheet, table.ref
+	wb.option.HeaderRow, wb.option.SkipFooter = 0, table.totalsRows
+	wb.option.NoHeader = table.headerRows == 0
+	df, err := wb.readSheet(table.sheet)
+	if err != nil {
+		return nil, err
+	}
+	// names of table columns are unique and not affected by formats of header cells, they are normalized by Header of option
+	if len(table.columns) == df.NCols() {
+		renamer := make(map[any]string, len(table.columns))
+		for i, name := range wb.option.Header.normalize(table.columns) {
+			renamer[i] = name
+		}
+		df.Rename(renamer, true)
+	}
+	return df, nil
+}
+
+// ReadXlsxDefinedName reads the range referred by a defined name, a name of workbook scope is preferred to one of sheet scope.
+// Sheet, SheetIndex and Range of option are determined by the name.
+func ReadXlsxDefinedName(r io.Reader, name string, option ...ReadXlsxOption) (*DataFrame[any], error) {
+	wb, err := openXlsx(r, firstReadXlsxOption(option))
+	if err != nil {
+		return nil, err
+	}
+
+	var refersTo string
+	for _, definedName := range wb.file.GetDefinedName() {
+		if !strings.EqualFold(definedName.Name, name) {
+			continue
+		}
+		if refersTo == "" || definedName.Scope == "Workbook" {
+			refersTo = definedName.RefersTo
+		}
+	}
+	if refersTo == "" {
+		return nil, newError("ReadXlsxDefinedName", -1, "", ErrNameNotFound, fmt.Errorf("defined name %q", name))
 	}
+	sheet, ref, err := parseSheetRef(refersTo)
+	if err != nil {
+		return nil, newError("ReadXlsxDefinedName", -1, "", ErrInvalidExpr, err)
+	}
+
+	wb.option.Sheet, wb.option.Range = sheet, ref
+	return wb.readSheet(sheet)
+}
+
+func firstReadXlsxOption(option []ReadXlsxOption) ReadXlsxOption {
+	if len(option) == 0 {
+		return ReadXlsxOption{}
+	}
+	return option[0]
+}
+
+// parseSheetRef parses a reference like "Sheet1!$A$1:$C$10" or "='My Sheet'!$A$1:$C$10" into the sheet and the range
+func parseSheetRef(ref string) (sheet string, cells string, err error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "=")
+	i := strings.LastIndexByte(ref, '!')
+	if i < 0 {
+		return "", "", fmt.Errorf("%q does not refer to a range", ref)
+	}
+	sheet, cells = ref[:i], ref[i+1:]
+	if strings.ContainsAny(cells, ",()") {
+		return "", "", fmt.Errorf("%q does not refer to a single range", ref)
+	}
+	if len(sheet) >= 2 && sheet[0] == '\'' && sheet[len(sheet)-1] == '\'' {
+		sheet = sheet[1 : len(sheet)-1]
+		if strings.Contains(strings.ReplaceAll(sheet, "''", ""), "'") {
+			return "", "", fmt.Errorf("%q does not refer to a single range", ref)
+		}
+		sheet = strings.ReplaceAll(sheet, "''", "'")
+	} else if strings.ContainsAny(sheet, ",!'") {
+		return "", "", fmt.Errorf("%q does not refer to a single range", ref)
+	}
+	return sheet, cells, nil
+}
+
+// xlsxWorkbook reads sheets of a workbook by excelize, and types of cells by readXlsxCells if native types or formulas are needed
+type xlsxWorkbook struct {
+	file   *excelize.File
+	dates  []bool
+	option ReadXlsxOption
+}
 
-	records, err := f.GetRows(sheet)
+func openXlsx(r io.Reader, option ReadXlsxOption) (*xlsxWorkbook, error) {
+	if err := option.Number.check(); err != nil {
+		return nil, err
+	}
+	options := excelize.Options{
+		Password:     option.Password,
+		RawCellValue: option.RawCellValue,
+	}
+	if option.NativeTypes || option.Formula != FormulaValue {
+		// worksheets are kept in memory instead of temporary files for readXlsxCells
+		options.UnzipXMLSizeLimit = excelize.UnzipSizeLimit
+	}
+	f, err := excelize.OpenReader(r, options)
 	if err != nil {
 		return nil, err
 	}
+	return &xlsxWorkbook{file: f, option: option}, nil
+}
+
+func (wb *xlsxWorkbook) readSheet(sheet string) (*DataFrame[any], error) {
+	if wb.option.NativeTypes {
+		records, err := wb.nativeRows(sheet)
+		if err != nil {
+			return nil, err
+		}
+		merges, err := wb.merges(sheet)
+		if err != nil {
+			return nil, err
+		}
+		return readNativeRows(records, merges, wb.option)
+	}
+
+	records, err := wb.file.GetRows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	if wb.option.Formula != FormulaValue {
+		if records, err = wb.formulaRows(sheet, records); err != nil {
+			return nil, err
+		}
+	}
+	merges, err := wb.merges(sheet)
+	if err != nil {
+		return nil, err
+	}
+	return readStringRows(records, merges, wb.option)
+}
+
+// merges returns merged ranges of a sheet if FillMerged of option is true
+func (wb *xlsxWorkbook) merges(sheet string) ([]excelize.MergeCell, error) {
+	if !wb.option.FillMerged {
+		return nil, nil
+	}
+	return wb.file.GetMergeCells(sheet)
+}
+
+// readStringRows reads formatted cells of a sheet into a dataframe by option, values are inferred like ReadSlice
+func readStringRows(records [][]string, merges []excelize.MergeCell, option ReadXlsxOption) (*DataFrame[any], error) {
+	header, data, err := xlsxColumns(records, merges, option)
+	if err != nil || header == nil {
+		return NewDataFrame[any](), err
+	}
+	for i := range data {
+		data[i] = append([]string{header[i]}, data[i]...)
+	}
+	return TryReadSlice(data, true, ReadSliceOption{Header: option.Header, Number: option.Number, Inference: option.Inference})
+}
+
+// readNativeRows reads cells of a sheet by their types into a dataframe by option
+func readNativeRows(records [][]any, merges []excelize.MergeCell, option ReadXlsxOption) (*DataFrame[any], error) {
+	header, data, err := xlsxColumns(records, merges, option)
+	if err != nil || header == nil {
+		return NewDataFrame[any](), err
+	}
+	names := make([]string, 0, len(header))
+	for _, name := range header {
+		names = append(names, cellString(name))
+	}
+	return readNativeColumns(option.Header.normalize(names), data)
+}
 
+// nativeRows reads cells of a sheet by their types, null values of Inference are nil
+func (wb *xlsxWorkbook) nativeRows(sheet string) ([][]any, error) {
+	values, err := wb.file.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	cells, err := readXlsxCells(wb.file, sheet)
+	if err != nil {
+		return nil, err
+	}
+	if wb.dates == nil {
+		wb.dates = dateStyles(wb.file)
+	}
+	var (
+		parser   = newValueParser(wb.option.Number, wb.option.Inference)
+		date1904 = xlsxDate1904(wb.file)
+		records  = make([][]any, len(values))
+	)
+	for i, row := range values {
+		records[i] = make([]any, len(row))
+		for j, value := range row {
+			var (
+				val  any
+				cell xlsxCell
+			)
+			if i < len(cells) && j < len(cells[i]) {
+				cell = cells[i][j]
+			}
+			if cell.formula && wb.option.Formula != FormulaValue {
+				s, err := wb.formula(sheet, j+1, i+1)
+				if err != nil {
+					return nil, err
+				}
+				if wb.option.Formula == FormulaText {
+					val = s
+				} else {
+					val = numericValue(s)
+				}
+			} else if val, err = nativeValue(value, cell, wb.dates, date1904); err != nil {
+				cellName, _ := excelize.CoordinatesToCellName(j+1, i+1)
+				return nil, newError("ReadXlsx", i, cellName, ErrConversion, err)
+			}
+			if s, ok := val.(string); ok && parser.isNull(s) {
+				val = nil
+			}
+			records[i][j] = val
+		}
+	}
+	return records, nil
+}
+
+// formulaRows replaces cached values of formula cells by formula texts or evaluated values
+func (wb *xlsxWorkbook) formulaRows(sheet string, records [][]string) ([][]string, error) {
+	cells, err := readXlsxCells(wb.file, sheet)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range cells {
+		for j, cell := range row {
+			if !cell.formula {
+				continue
+			}
+			s, err := wb.formula(sheet, j+1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			for len(records) <= i {
+				records = append(records, nil)
+			}
+			for len(records[i]) <= j {
+				records[i] = append(records[i], "")
+			}
+			records[i][j] = s
+		}
+	}
+	return records, nil
+}
+
+// formula returns the formula text or the evaluated value of a cell by Formula of option
+func (wb *xlsxWorkbook) formula(sheet string, col, row int) (string, error) {
+	cell, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return "", err
+	}
+	if wb.option.Formula == FormulaText {
+		formula, err := wb.file.GetCellFormula(sheet, cell)
+		if err != nil || strings.HasPrefix(formula, "=") {
+			return formula, err
+		}
+		return "=" + formula, nil
+	}
+	val, err := wb.file.CalcCellValue(sheet, cell)
+	if err != nil {
+		return "", newError("ReadXlsx", row-1, cell, ErrInvalidExpr, err)
+	}
+	return val, nil
+}
+
+// xlsxColumns applies FillMerged, Range, HeaderRow, SkipFooter, NoHeader and BadLines of option to rows of a sheet,
+// and returns the header and the columns, header is nil if there is no row
+func xlsxColumns[T any](records [][]T, merges []excelize.MergeCell, option ReadXlsxOption) ([]T, [][]T, error) {
+	if option.FillMerged {
+		var err error
+		if records, err = fillMerged(records, merges); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// rows and columns of the range, 0 of toRow or toCol means the last one
+	var (
+		fromCol, fromRow, toCol, toRow = 1, 1, 0, 0
+		err                            error
+	)
+	if option.Range != "" {
+		if fromCol, fromRow, toCol, toRow, err = parseCellRange(option.Range); err != nil {
+			return nil, nil, newError("ReadXlsx", -1, "", ErrInvalidExpr, err)
+		}
+	}
+	if toRow == 0 || toRow > len(records) {
+		toRow = len(records)
+	}
+	// line of records[0] in the sheet
+	line := fromRow + option.HeaderRow
+	if line > toRow {
+		// empty sheet or range
+		return nil, nil, nil
+	}
+	records = records[line-1 : toRow]
+	if option.SkipFooter >= len(records) {
+		records = nil
+	} else {
+		records = records[:len(records)-option.SkipFooter]
+	}
+	for i, row := range records {
+		records[i] = cutRow(row, fromCol, toCol)
+	}
+
+	var (
+		header []T
+		blank  T
+	)
 	if len(records) == 0 {
-		// empty sheet
-		return NewDataFrame[any](), nil
+		return nil, nil, nil
+	} else if !option.NoHeader {
+		header, records = records[0], records[1:]
+		line++
+	} else {
+		n := 0
+		for _, row := range records {
+			if len(row) > n {
+				n = len(row)
+			}
+		}
+		header = make([]T, n)
 	}
 
-	data := make([][]string, len(records[0]))
-	for _, row := range records {
-		for ncol, val := range row {
-			data[ncol] = append(data[ncol], val)
+	n := len(header)
+	if toCol != 0 {
+		// the header row may have trailing empty cells in the range
+		n = toCol - fromCol + 1
+	}
+	for len(header) < n {
+		header = append(header, blank)
+	}
+	data := make([][]T, n)
+	for i := range data {
+		data[i] = make([]T, 0, len(records))
+	}
+	for i, row := range records {
+		if len(row) > n {
+			fixed, err := handleBadLine("ReadXlsx", option.BadLines, option.OnBadLine, cellStrings(row), n, line+i, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			if fixed == nil {
+				continue
+			}
+			row = row[:n]
+		}
+		for ncol := 0; ncol < n; ncol++ {
+			if ncol < len(row) {
+				data[ncol] = append(data[ncol], row[ncol])
+			} else {
+				data[ncol] = append(data[ncol], blank)
+			}
 		}
 	}
-	return ReadSlice(data, true), nil
+	if option.NoHeader {
+		for i := range header {
+			header[i] = any(strconv.Itoa(i)).(T)
+		}
+	}
+	return header, data, nil
+}
+
+// fillMerged fills every cell of merged ranges with the value of the top-left cell
+func fillMerged[T any](records [][]T, merges []excelize.MergeCell) ([][]T, error) {
+	for _, merge := range merges {
+		fromCol, fromRow, err := excelize.CellNameToCoordinates(merge.GetStartAxis())
+		if err != nil {
+			return nil, err
+		}
+		toCol, toRow, err := excelize.CellNameToCoordinates(merge.GetEndAxis())
+		if err != nil {
+			return nil, err
+		}
+		if fromRow > len(records) || fromCol > len(records[fromRow-1]) {
+			// empty merged cell
+			continue
+		}
+		val := records[fromRow-1][fromCol-1]
+		for len(records) < toRow {
+			records = append(records, nil)
+		}
+		for row := fromRow; row <= toRow; row++ {
+			for len(records[row-1]) < toCol {
+				var blank T
+				records[row-1] = append(records[row-1], blank)
+			}
+			for col := fromCol; col <= toCol; col++ {
+				records[row-1][col-1] = val
+			}
+		}
+	}
+	return records, nil
+}
+
+// readNativeColumns converts columns of native values into a dataframe like readColumns,
+// numbers are int64 if all numbers of a column are integers, otherwise float64
+func readNativeColumns(header []string, data [][]any) (*DataFrame[any], error) {
+	seriess := make([]*Series[any], 0, len(header))
+	for i, values := range data {
+		seriess = append(seriess, NewSeries(header[i], nativeElements(values)...))
+	}
+	return TryNewDataFrame(seriess...)
+}
+
+func nativeElements(values []any) []any {
+	var (
+		kind     reflect.Kind
+		mixed    bool
+		integers = true
+	)
+	for _, val := range values {
+		if val == nil {
+			continue
+		}
+		k := reflect.TypeOf(val).Kind()
+		if kind == reflect.Invalid {
+			kind = k
+		} else if kind != k {
+			mixed = true
+		}
+		if v, ok := val.(float64); ok && (v != math.Trunc(v) || math.Abs(v) > 1<<53) {
+			integers = false
+		}
+	}
+
+	elements := make([]any, 0, len(values))
+	for _, val := range values {
+		switch {
+		case val == nil && (mixed || kind == reflect.Invalid || (kind == reflect.Float64 && !integers)):
+			elements = append(elements, math.NaN())
+		case val == nil:
+			elements = append(elements, nil)
+		case kind == reflect.Float64 && !mixed && integers:
+			elements = append(elements, int64(val.(float64)))
+		default:
+			elements = append(elements, val)
+		}
+	}
+	return elements
+}
+
+// cellString formats a native value as a string, e.g. for column names
+func cellString(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatNativeValue formats a native value for inference, dates are formatted without the time if it is midnight
+func formatNativeValue(val any) string {
+	switch v := val.(type) {
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04:05")
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return cellString(v)
+	}
+}
+
+func cellStrings[T any](row []T) []string {
+	values := make([]string, 0, len(row))
+	for _, val := range row {
+		values = append(values, cellString(val))
+	}
+	return values
+}
+
+// cutRow returns cells of row from column fromCol to toCol, 0 of toCol means the last one
+func cutRow[T any](row []T, fromCol, toCol int) []T {
+	if fromCol > len(row) {
+		return nil
+	}
+	if toCol != 0 && toCol < len(row) {
+		row = row[:toCol]
+	}
+	return row[fromCol-1:]
+}
+
+// parseCellRange parses an A1-style range like "B3:F200", "$B$3:$F$200", "B:F" or "3:200",
+// 0 of toCol and toRow means the last column and the last row
+func parseCellRange(ref string) (fromCol, fromRow, toCol, toRow int, err error) {
+	from, to, ok := strings.Cut(strings.ReplaceAll(ref, "$", ""), ":")
+	if !ok {
+		to = from
+	}
+	if fromCol, fromRow, err = parseCellRef(from); err != nil {
+		return
+	}
+	if toCol, toRow, err = parseCellRef(to); err != nil {
+		return
+	}
+	if fromCol == 0 {
+		fromCol = 1
+	}
+	if fromRow == 0 {
+		fromRow = 1
+	}
+	if (toCol != 0 && toCol < fromCol) || (toRow != 0 && toRow < fromRow) {
+		err = fmt.Errorf("invalid range %q", ref)
+	}
+	return
+}
+
+// parseCellRef parses a cell like "B3", a column like "B" or a row like "3", the missing one is 0
+func parseCellRef(ref string) (col, row int, err error) {
+	if ref == "" {
+		return 0, 0, fmt.Errorf("invalid range %q", ref)
+	}
+	if row, err = strconv.Atoi(ref); err == nil && row > 0 {
+		return 0, row, nil
+	}
+	if col, err = excelize.ColumnNameToNumber(ref); err == nil {
+		return col, 0, nil
+	}
+	return excelize.CellNameToCoordinates(ref)
 }
@@ -1,14 +1,21 @@
 package pandat
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"github.com/xuri/excelize/v2"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestReadExcel(t *testing.T) {
 	f, err := os.Open("/Users/tanyaofei/Desktop/测试数据/1.xlsx")
-	if err != nil {
+	if os.IsNotExist(err) {
+		t.Skip(err)
+	} else if err != nil {
 		panic(err)
 	}
 	df, err := ReadXlsx(f, ReadXlsxOption{})
@@ -18,3 +25,269 @@ func TestReadExcel(t *testing.T) {
 	fmt.Println(df)
 	fmt.Println(df.DTypes())
 }
+
+func TestReadXlsxBadLines(t *testing.T) {
+	f := excelize.NewFile()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]any{"a", "b"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]any{1, 2})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]any{3})
+	_ = f.SetSheetRow("Sheet1", "A4", &[]any{4, 5, 6})
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{}); !errors.Is(err, ErrBadLine) {
+		t.Fatalf("expected ErrBadLine, got %v", err)
+	}
+
+	df, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{BadLines: BadLinePad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df.NRows() != 3 || df.Val(1, "b") != nil || df.Val(2, "b") != int64(5) {
+		t.Fatalf("unexpected rows: %v", df.Seriess().Slice())
+	}
+}
+
+func TestReadXlsxMissingSheet(t *testing.T) {
+	f := excelize.NewFile()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]any{"a"})
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Sheet: "missing"}); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("expected ErrNameNotFound, got %v", err)
+	}
+	if _, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{SheetIndex: 9}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Sheet: "sheet1"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadXlsxRange(t *testing.T) {
+	f := excelize.NewFile()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]any{"report"})
+	_ = f.SetSheetRow("Sheet1", "B3", &[]any{"a", "b", "c"})
+	_ = f.SetSheetRow("Sheet1", "B4", &[]any{1, "x", 1.5})
+	_ = f.SetSheetRow("Sheet1", "B5", &[]any{2, "y", 2.5})
+	_ = f.SetSheetRow("Sheet1", "B6", &[]any{"total", "", 4})
+	f.NewSheet("Sheet2")
+	_ = f.SetSheetRow("Sheet2", "A1", &[]any{1, 2})
+	_ = f.SetSheetRow("Sheet2", "A2", &[]any{3})
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	df, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Range: "B3:C200", SkipFooter: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"a", "b"}) || df.NRows() != 2 || df.Val(1, "a") != int64(2) {
+		t.Fatalf("unexpected dataframe: %v", df.Seriess().Slice())
+	}
+
+	df, err = ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{HeaderRow: 2, SkipFooter: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"", "a", "b", "c"}) || df.NRows() != 2 || df.Val(0, "c") != 1.5 {
+		t.Fatalf("unexpected dataframe: %v", df.Seriess().Slice())
+	}
+
+	df, err = ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Sheet: "Sheet2", NoHeader: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"0", "1"}) || df.NRows() != 2 || df.Val(1, "1") != nil {
+		t.Fatalf("unexpected dataframe: %v", df.Seriess().Slice())
+	}
+
+	dfs, err := ReadXlsxAll(bytes.NewReader(buf.Bytes()), ReadXlsxOption{NoHeader: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dfs) != 2 || dfs["Sheet1"].NRows() != 6 || dfs["Sheet2"].NRows() != 2 {
+		t.Fatalf("unexpected dataframes: %v", dfs)
+	}
+
+	if _, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Range: "F3:B1"}); !errors.Is(err, ErrInvalidExpr) {
+		t.Fatalf("expected ErrInvalidExpr, got %v", err)
+	}
+}
+
+func TestParseCellRange(t *testing.T) {
+	cases := map[string][4]int{
+		"B3:F200":     {2, 3, 6, 200},
+		"$B$3:$F$200": {2, 3, 6, 200},
+		"B:F":         {2, 1, 6, 0},
+		"3:200":       {1, 3, 0, 200},
+		"C5":          {3, 5, 3, 5},
+	}
+	for ref, expected := range cases {
+		fromCol, fromRow, toCol, toRow, err := parseCellRange(ref)
+		if err != nil || [4]int{fromCol, fromRow, toCol, toRow} != expected {
+			t.Fatalf("%s: expected %v, got %v %v", ref, expected, [4]int{fromCol, fromRow, toCol, toRow}, err)
+		}
+	}
+}
+
+func TestReadXlsxNativeTypes(t *testing.T) {
+	f := excelize.NewFile()
+	day := time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)
+	_ = f.SetSheetRow("Sheet1", "A1", &[]any{"code", "amount", "date", "ok", "group", "total"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]any{"00123", 1, day, true, "g1"})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]any{"00456", 2, day.AddDate(0, 0, 1), false})
+	_ = f.SetCellFormula("Sheet1", "F2", "SUM(B2:B3)")
+	_ = f.MergeCell("Sheet1", "E2", "E3")
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	df, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{NativeTypes: true, FillMerged: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := df.Val(0, "code"); code != "00123" {
+		t.Fatalf("expected code kept as string, got %v", code)
+	}
+	if amount := df.Val(1, "amount"); amount != int64(2) {
+		t.Fatalf("expected int64 amount, got %#v", amount)
+	}
+	if date, ok := df.Val(0, "date").(time.Time); !ok || !date.Equal(day) {
+		t.Fatalf("expected date %v, got %#v", day, df.Val(0, "date"))
+	}
+	if ok := df.Val(1, "ok"); ok != false {
+		t.Fatalf("expected false, got %#v", ok)
+	}
+	if group := df.Val(1, "group"); group != "g1" {
+		t.Fatalf("expected merged cell filled, got %#v", group)
+	}
+	// the formula has no cached value
+	if total := df.Val(0, "total"); !isNull(total) {
+		t.Fatalf("expected null total, got %#v", total)
+	}
+
+	df, err = ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{NativeTypes: true, Formula: FormulaEvaluate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := df.Val(0, "total"); total != int64(3) {
+		t.Fatalf("expected evaluated total, got %#v", total)
+	}
+	if group := df.Val(1, "group"); group != nil {
+		t.Fatalf("expected merged cell not filled, got %#v", group)
+	}
+
+	df, err = ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Formula: FormulaText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := df.Val(0, "total"); total != "=SUM(B2:B3)" {
+		t.Fatalf("expected formula text, got %#v", total)
+	}
+}
+
+func TestIsDateFormat(t *testing.T) {
+	cases := map[string]bool{
+		"yyyy-mm-dd":              true,
+		`yyyy"年"m"月"d"日"`:         true,
+		"[h]:mm:ss":               true,
+		"hh:mm AM/PM":             true,
+		"0.00":                    false,
+		"#,##0.00;[Red]-#,##0.00": false,
+		"0.00E+00":                false,
+		"General":                 false,
+		`0.0" days"`:              false,
+		`\d0`:                     false,
+	}
+	for code, expected := range cases {
+		if isDateFormat(code) != expected {
+			t.Fatalf("%s: expected %v", code, expected)
+		}
+	}
+}
+
+func TestReadXlsxTable(t *testing.T) {
+	f := excelize.NewFile()
+	f.NewSheet("Finance")
+	_ = f.SetSheetRow("Finance", "A1", &[]any{"title"})
+	_ = f.SetSheetRow("Finance", "B3", &[]any{"month", "income"})
+	_ = f.SetSheetRow("Finance", "B4", &[]any{"Jan", 100})
+	_ = f.SetSheetRow("Finance", "B5", &[]any{"Feb", 200})
+	_ = f.SetSheetRow("Finance", "E3", &[]any{"region", "cost"})
+	_ = f.SetSheetRow("Finance", "E4", &[]any{"north", 1.5})
+	_ = f.SetSheetRow("Finance", "H3", &[]any{"unit price", "qty"})
+	_ = f.SetSheetRow("Finance", "H4", &[]any{1.5, 2})
+	if err := f.AddTable("Finance", "B3", "C5", `{"table_name":"Income"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.AddTable("Finance", "E3", "F4", `{"table_name":"Cost"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.AddTable("Finance", "H3", "I4", `{"table_name":"Prices"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: "Months", RefersTo: "Finance!$B$3:$B$5"}); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	df, err := ReadXlsxTable(bytes.NewReader(buf.Bytes()), "income")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"month", "income"}) || df.NRows() != 2 || df.Val(1, "income") != int64(200) {
+		t.Fatalf("unexpected dataframe: %v", df.Seriess().Slice())
+	}
+
+	df, err = ReadXlsxTable(bytes.NewReader(buf.Bytes()), "Cost", ReadXlsxOption{NativeTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"region", "cost"}) || df.NRows() != 1 || df.Val(0, "cost") != 1.5 {
+		t.Fatalf("unexpected dataframe: %v", df.Seriess().Slice())
+	}
+
+	df, err = ReadXlsxTable(bytes.NewReader(buf.Bytes()), "Prices", ReadXlsxOption{Header: HeaderOption{Sanitize: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := df.Names(); !reflect.DeepEqual(names, []string{"unit_price", "qty"}) {
+		t.Fatalf("expected sanitized names, got %v", names)
+	}
+
+	df, err = ReadXlsxDefinedName(bytes.NewReader(buf.Bytes()), "months")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"month"}) || df.NRows() != 2 || df.Val(0, "month") != "Jan" {
+		t.Fatalf("unexpected dataframe: %v", df.Seriess().Slice())
+	}
+
+	if _, err := ReadXlsxTable(bytes.NewReader(buf.Bytes()), "missing"); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("expected ErrNameNotFound, got %v", err)
+	}
+	if _, err := ReadXlsxDefinedName(bytes.NewReader(buf.Bytes()), "missing"); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("expected ErrNameNotFound, got %v", err)
+	}
+}
+
+func TestParseSheetRef(t *testing.T) {
+	sheet, cells, err := parseSheetRef("='My ''Q1'' Sheet'!$A$1:$C$10")
+	if err != nil || sheet != "My 'Q1' Sheet" || cells != "$A$1:$C$10" {
+		t.Fatalf("unexpected reference: %s %s %v", sheet, cells, err)
+	}
+	if _, _, err := parseSheetRef("Sheet1!$A$1,Sheet1!$B$2"); err == nil {
+		t.Fatalf("expected error for multiple ranges")
+	}
+}
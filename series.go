@@ -158,6 +158,19 @@ func (s *Series[E]) SubSeriesByIndexer(indexer map[int]struct{}) *Series[E] {
 	}
 }
 
+// take returns a series with the values at given indexes in the given order
+func (s *Series[E]) take(indexes []int) *Series[E] {
+	elements := make([]E, 0, len(indexes))
+	for _, i := range indexes {
+		elements = append(elements, s.elements[i])
+	}
+	return &Series[E]{
+		elements: elements,
+		name:     s.name,
+		dtype:    s.dtype,
+	}
+}
+
 func (s *Series[E]) Range(fn func(i int, val E)) {
 	for i, e := range s.elements {
 		fn(i, e)
@@ -222,8 +235,17 @@ func (s *Series[E]) Reduce(reducer func(left E, right E) E) (E, bool) {
 }
 
 func (s *Series[E]) Int() *Series[int] {
+	series, err := s.TryInt()
+	if err != nil {
+		panic(err)
+	}
+	return series
+}
+
+// TryInt is like Int but returns ErrConversion with the row of the value instead of panic
+func (s *Series[E]) TryInt() (*Series[int], error) {
 	elements := make([]int, 0, len(s.elements))
-	for _, val := range s.elements {
+	for i, val := range s.elements {
 		switch v := any(val).(type) {
 		case uint:
 			elements = append(elements, int(v))
@@ -275,29 +297,47 @@ func (s *Series[E]) Int() *Series[int] {
 			elements = append(elements, int(*v))
 		case string:
 			if v, err := strconv.Atoi(v); err != nil {
-				panic(err)
+				return nil, newError("Series.Int", i, s.name, ErrConversion, err)
 			} else {
 				elements = append(elements, v)
 			}
 		case *string:
 			if v, err := strconv.Atoi(*v); err != nil {
-				panic(err)
+				return nil, newError("Series.Int", i, s.name, ErrConversion, err)
 			} else {
 				elements = append(elements, v)
 			}
 		default:
-			panic("can not convert to float64")
+			return nil, newError("Series.Int", i, s.name, ErrConversion, fmt.Errorf("unsupported type %T", v))
 		}
 	}
 	return &Series[int]{
 		elements: elements,
 		name:     s.name,
-	}
+	}, nil
 }
 
 func (s *Series[E]) Int64() *Series[int64] {
+	series, err := s.TryInt64()
+	if err != nil {
+		panic(err)
+	}
+	return series
+}
+
+// TryInt64 is like Int64 but returns ErrConversion with the row of the value instead of panic
+func (s *Series[E]) TryInt64() (*Series[int64], error) {
+	if values, ok := any(s.elements).([]int64); ok {
+		elements := make([]int64, len(values))
+		copy(elements, values)
+		return &Series[int64]{
+			elements: elements,
+			name:     s.name,
+		}, nil
+	}
+
 	elements := make([]int64, 0, len(s.elements))
-	for _, val := range s.elements {
+	for i, val := range s.elements {
 		switch v := any(val).(type) {
 		case uint:
 			elements = append(elements, int64(v))
@@ -349,30 +389,49 @@ func (s *Series[E]) Int64() *Series[int64] {
 			elements = append(elements, int64(*v))
 		case string:
 			if v, err := strconv.ParseInt(v, 10, 64); err != nil {
-				panic(err)
+				return nil, newError("Series.Int64", i, s.name, ErrConversion, err)
 			} else {
 				elements = append(elements, v)
 			}
 		case *string:
 			if v, err := strconv.ParseInt(*v, 10, 64); err != nil {
-				panic(err)
+				return nil, newError("Series.Int64", i, s.name, ErrConversion, err)
 			} else {
 				elements = append(elements, v)
 			}
 		default:
-			panic("can not convert to float64")
+			return nil, newError("Series.Int64", i, s.name, ErrConversion, fmt.Errorf("unsupported type %T", v))
 		}
 	}
 	return &Series[int64]{
 		elements: elements,
 		name:     s.name,
-	}
+	}, nil
 }
 
 func (s *Series[E]) Float64() *Series[float64] {
+	series, err := s.TryFloat64()
+	if err != nil {
+		panic(err)
+	}
+	return series
+}
+
+// TryFloat64 is like Float64 but returns ErrConversion with the row of the value instead of panic
+func (s *Series[E]) TryFloat64() (*Series[float64], error) {
+	if values, ok := any(s.elements).([]float64); ok {
+		// fast path for unboxed values, copied because callers may sort the result in place
+		elements := make([]float64, len(values))
+		copy(elements, values)
+		return &Series[float64]{
+			elements: elements,
+			name:     s.name,
+		}, nil
+	}
+
 	elements := make([]float64, 0, len(s.elements))
 
-	for _, val := range s.elements {
+	for i, val := range s.elements {
 		switch v := any(val).(type) {
 		case uint:
 			elements = append(elements, float64(v))
@@ -424,24 +483,24 @@ func (s *Series[E]) Float64() *Series[float64] {
 			elements = append(elements, *v)
 		case string:
 			if v, err := strconv.ParseFloat(v, 64); err != nil {
-				panic(err)
+				return nil, newError("Series.Float64", i, s.name, ErrConversion, err)
 			} else {
 				elements = append(elements, v)
 			}
 		case *string:
 			if v, err := strconv.ParseFloat(*v, 64); err != nil {
-				panic(err)
+				return nil, newError("Series.Float64", i, s.name, ErrConversion, err)
 			} else {
 				elements = append(elements, v)
 			}
 		default:
-			panic(fmt.Sprintf("can not convert '%s' type '%t' to float64", v, v))
+			return nil, newError("Series.Float64", i, s.name, ErrConversion, fmt.Errorf("unsupported type %T", v))
 		}
 	}
 	return &Series[float64]{
 		elements: elements,
 		name:     s.name,
-	}
+	}, nil
 }
 
 func (s *Series[E]) Str() *Series[string] {
@@ -626,6 +685,45 @@ func (s *Series[E]) String() string {
 	return s.Print(10, true)
 }
 
+// isNull returns true if val is nil, a nil pointer or NaN
+func isNull(val any) bool {
+	if val == nil {
+		return true
+	}
+	ref := reflect.ValueOf(val)
+	if ref.Kind() == reflect.Pointer {
+		if ref.IsNil() {
+			return true
+		}
+		ref = ref.Elem()
+	}
+	switch ref.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(ref.Float())
+	}
+	return false
+}
+
+// nullableFloat64 converts a number or a pointer of number to float64, ok is false if val is null or not a number
+func nullableFloat64(val any) (float64, bool) {
+	if isNull(val) {
+		return 0, false
+	}
+	ref := reflect.ValueOf(val)
+	if ref.Kind() == reflect.Pointer {
+		ref = ref.Elem()
+	}
+	switch ref.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(ref.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(ref.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return ref.Float(), true
+	}
+	return 0, false
+}
+
 func NewSeries[E any](name string, vals ...E) *Series[E] {
 	s := Series[E]{
 		elements: vals,
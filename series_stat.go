@@ -1,9 +1,14 @@
 package pandat
 
 import (
+	"fmt"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/stat"
 	"math"
+	"reflect"
 	"sort"
+	"strconv"
+	"time"
 )
 
 func (s *Series[E]) Quantile(p float64) float64 {
@@ -47,34 +52,492 @@ func (s *Series[E]) Median() float64 {
 	return (data[len(data)/2-1] + data[len(data)/2]) * 0.5
 }
 
+// Mode returns the most frequent values in order of first appearance.
+// Null values, i.e. nil, nil pointers and NaN, are not counted like pandas, so a series of nulls has no modes.
 func (s *Series[E]) Mode() []E {
-	seen := make(map[any]int, s.Len())
+	values, counts := s.valueCounts(true)
 
 	max := 0
-	for _, val := range s.elements {
-		var count int
-		if _, ok := seen[val]; ok {
-			count = seen[val] + 1
-		} else {
-			count = 1
-		}
-		seen[val] = count
+	for _, count := range counts {
 		if count > max {
 			max = count
 		}
 	}
 
 	modes := make([]E, 0)
-	for val, count := range seen {
+	for i, count := range counts {
 		if count == max {
-			switch v := val.(type) {
-			case E:
-				modes = append(modes, v)
+			modes = append(modes, values[i])
+		}
+	}
+
+	return modes
+}
+
+type ValueCountsOption struct {
+	// Normalize returns proportions of values instead of counts
+	Normalize bool
+	// NoSort keeps values in order of first appearance, otherwise values are sorted by counts in descending order
+	NoSort bool
+	// Ascending sorts counts in ascending order, does not work with NoSort
+	Ascending bool
+	// DropNa excludes null values
+	DropNa bool
+	// Bins groups numeric values into given number of equal-width bins instead of counting distinct values
+	Bins int
+}
+
+// IndexedSeries is a series whose elements are labeled by an index, e.g. counts labeled by values
+type IndexedSeries[E any] struct {
+	*Series[E]
+	index []any
+}
+
+// Index returns labels of elements in the same order
+func (s *IndexedSeries[E]) Index() []any {
+	return s.index
+}
+
+// Loc returns the element labeled by label, null labels match each other, ok is false if not found
+func (s *IndexedSeries[E]) Loc(label any) (E, bool) {
+	null := isNull(label)
+	for i, l := range s.index {
+		if (null && isNull(l)) || (!null && l == label) {
+			return s.elements[i], true
+		}
+	}
+	return *new(E), false
+}
+
+// ValueCounts returns counts of unique values indexed by the values, the series is named "count",
+// or "proportion" if option.Normalize is true. All null values are counted as the first null value.
+func (s *Series[E]) ValueCounts(option ValueCountsOption) *IndexedSeries[float64] {
+	counts, err := s.TryValueCounts(option)
+	if err != nil {
+		panic(err)
+	}
+	return counts
+}
+
+// TryValueCounts is like ValueCounts but returns ErrConversion instead of panic if a value can not be binned
+func (s *Series[E]) TryValueCounts(option ValueCountsOption) (*IndexedSeries[float64], error) {
+	var (
+		values []any
+		counts []int
+	)
+	if option.Bins > 0 {
+		var err error
+		if values, counts, err = s.binCounts(option.Bins, option.DropNa); err != nil {
+			return nil, err
+		}
+	} else {
+		vals, cnts := s.valueCounts(option.DropNa)
+		values = make([]any, 0, len(vals))
+		for _, val := range vals {
+			values = append(values, val)
+		}
+		counts = cnts
+	}
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	if !option.NoSort {
+		// stable sort keeps the order of first appearance for values with the same count
+		sort.SliceStable(order, func(i, j int) bool {
+			if option.Ascending {
+				return counts[order[i]] < counts[order[j]]
+			}
+			return counts[order[i]] > counts[order[j]]
+		})
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	index := make([]any, 0, len(order))
+	elements := make([]float64, 0, len(order))
+	for _, i := range order {
+		index = append(index, values[i])
+		if option.Normalize {
+			elements = append(elements, float64(counts[i])/float64(total))
+		} else {
+			elements = append(elements, float64(counts[i]))
+		}
+	}
+
+	name := "count"
+	if option.Normalize {
+		name = "proportion"
+	}
+	return &IndexedSeries[float64]{Series: NewSeries(name, elements...), index: index}, nil
+}
+
+// valueCounts returns unique values in order of first appearance and their counts,
+// all null values are counted as the first null value
+func (s *Series[E]) valueCounts(dropNa bool) ([]E, []int) {
+	var (
+		index   = make(map[any]int, s.Len())
+		values  = make([]E, 0)
+		counts  = make([]int, 0)
+		nullIdx = -1
+	)
+	for _, val := range s.elements {
+		if isNull(val) {
+			if dropNa {
+				continue
+			}
+			if nullIdx < 0 {
+				nullIdx = len(values)
+				values = append(values, val)
+				counts = append(counts, 0)
+			}
+			counts[nullIdx]++
+			continue
+		}
+
+		if i, ok := index[val]; ok {
+			counts[i]++
+		} else {
+			index[val] = len(values)
+			values = append(values, val)
+			counts = append(counts, 1)
+		}
+	}
+	return values, counts
+}
+
+// binCounts groups values into equal-width half-open bins like (left, right] and counts them,
+// the lowest edge is extended by 0.1% of the range to include the minimum value
+func (s *Series[E]) binCounts(bins int, dropNa bool) ([]any, []int, error) {
+	data := make([]float64, 0, s.Len())
+	nulls := 0
+	for i, val := range s.elements {
+		if isNull(val) {
+			nulls++
+			continue
+		}
+		v, ok := nullableFloat64(val)
+		if !ok {
+			return nil, nil, newError("Series.ValueCounts", i, s.name, ErrConversion, fmt.Errorf("can not bin non-numeric value %v", val))
+		}
+		data = append(data, v)
+	}
+
+	values := make([]any, 0, bins+1)
+	counts := make([]int, 0, bins+1)
+	if len(data) > 0 {
+		min, max := floats.Min(data), floats.Max(data)
+		edges := make([]float64, bins+1)
+		if min == max {
+			// all values are the same, widen the range by 0.1% on both sides
+			min, max = min-math.Abs(min)*0.001, max+math.Abs(max)*0.001
+			if min == max {
+				min, max = -0.001, 0.001
+			}
+			floats.Span(edges, min, max)
+		} else {
+			floats.Span(edges, min, max)
+			edges[0] -= (max - min) * 0.001
+		}
+
+		counts = counts[:bins]
+		for _, v := range data {
+			i := sort.SearchFloat64s(edges, v) - 1
+			if i < 0 {
+				i = 0
+			} else if i >= bins {
+				i = bins - 1
+			}
+			counts[i]++
+		}
+		for i := 0; i < bins; i++ {
+			values = append(values, fmt.Sprintf("(%g, %g]", edges[i], edges[i+1]))
+		}
+	}
+
+	if !dropNa && nulls > 0 {
+		values = append(values, math.NaN())
+		counts = append(counts, nulls)
+	}
+	return values, counts, nil
+}
+
+// CorrMethod is the method of correlation coefficient
+type CorrMethod int
+
+const (
+	// Pearson standard correlation coefficient
+	Pearson CorrMethod = iota
+	// Spearman rank correlation
+	Spearman
+	// Kendall Tau-b correlation coefficient
+	Kendall
+)
+
+// Corr returns the correlation coefficient between this series and other.
+// Only the pairs of which both values are not null are used, NaN is returned if there are less than 2 pairs.
+func (s *Series[E]) Corr(other *Series[E], method CorrMethod) float64 {
+	x, y := pairwiseComplete(s, other)
+	if len(x) < 2 {
+		return math.NaN()
+	}
+
+	switch method {
+	case Pearson:
+		return stat.Correlation(x, y, nil)
+	case Spearman:
+		return stat.Correlation(rankFloat64s(x, RankAverage, true), rankFloat64s(y, RankAverage, true), nil)
+	case Kendall:
+		return kendallTauB(x, y)
+	default:
+		panic("pandat.series.Corr::unsupported method: " + strconv.Itoa(int(method)))
+	}
+}
+
+// Cov returns the sample covariance between this series and other.
+// Only the pairs of which both values are not null are used, NaN is returned if there are less than 2 pairs.
+func (s *Series[E]) Cov(other *Series[E]) float64 {
+	x, y := pairwiseComplete(s, other)
+	if len(x) < 2 {
+		return math.NaN()
+	}
+	return stat.Covariance(x, y, nil)
+}
+
+// isNumeric returns true if all not null values of the series are numbers
+func (s *Series[E]) isNumeric() bool {
+	numeric := false
+	for _, val := range s.elements {
+		if isNull(val) {
+			continue
+		}
+		if _, ok := nullableFloat64(val); !ok {
+			return false
+		}
+		numeric = true
+	}
+	return numeric
+}
+
+// pairwiseComplete returns float64 values of the two series where both values are not null
+func pairwiseComplete[E any](left, right *Series[E]) ([]float64, []float64) {
+	if left.Len() != right.Len() {
+		panic("pandat.series::length not match")
+	}
+
+	x := make([]float64, 0, left.Len())
+	y := make([]float64, 0, right.Len())
+	for i, l := range left.elements {
+		lv, ok := nullableFloat64(l)
+		if !ok {
+			continue
+		}
+		rv, ok := nullableFloat64(right.elements[i])
+		if !ok {
+			continue
+		}
+		x = append(x, lv)
+		y = append(y, rv)
+	}
+	return x, y
+}
+
+// kendallTauB returns the Tau-b Kendall correlation which is adjusted for ties
+func kendallTauB(x, y []float64) float64 {
+	var (
+		concordant, discordant float64
+		tiesX, tiesY           float64
+		n                      = len(x)
+	)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dx := x[j] - x[i]
+			dy := y[j] - y[i]
+			switch {
+			case dx == 0 && dy == 0:
+				// tied in both, counts in neither
+			case dx == 0:
+				tiesX++
+			case dy == 0:
+				tiesY++
+			case (dx > 0) == (dy > 0):
+				concordant++
 			default:
-				panic("impossible")
+				discordant++
 			}
 		}
 	}
 
-	return modes
+	denominator := math.Sqrt((concordant + discordant + tiesX) * (concordant + discordant + tiesY))
+	if denominator == 0 {
+		return math.NaN()
+	}
+	return (concordant - discordant) / denominator
+}
+
+// RankMethod is the method to rank the values with the same value
+type RankMethod int
+
+const (
+	// RankAverage assigns the average rank of the group
+	RankAverage RankMethod = iota
+	// RankMin assigns the lowest rank of the group
+	RankMin
+	// RankMax assigns the highest rank of the group
+	RankMax
+	// RankFirst assigns ranks in order of appearance
+	RankFirst
+	// RankDense is like RankMin, but ranks always increase by 1 between groups
+	RankDense
+)
+
+// Rank returns 1-based ranks of values, null values are ranked as NaN.
+// Numbers, strings and times are ranked by their orders, but values of different kinds can not be ranked together.
+// If pct is true, ranks are divided by the number of ranked values (or the highest rank for RankDense)
+func (s *Series[E]) Rank(method RankMethod, ascending bool, pct bool) *Series[float64] {
+	ranks, err := s.TryRank(method, ascending, pct)
+	if err != nil {
+		panic(err)
+	}
+	return ranks
+}
+
+// TryRank is like Rank but returns ErrConversion with the row of the value instead of panic,
+// if the value is not a number, string or time, or is not of the same kind as the former values.
+// ErrInvalidOption is returned for an unknown method.
+func (s *Series[E]) TryRank(method RankMethod, ascending bool, pct bool) (*Series[float64], error) {
+	if method < RankAverage || method > RankDense {
+		return nil, newError("Series.Rank", -1, s.name, ErrInvalidOption, fmt.Errorf("unknown rank method %d", method))
+	}
+	var (
+		elements  = make([]float64, s.Len())
+		keys      = make([]any, 0, s.Len())
+		positions = make([]int, 0, s.Len())
+	)
+	for i, val := range s.elements {
+		if isNull(val) {
+			elements[i] = math.NaN()
+			continue
+		}
+		var key any
+		if v, ok := nullableFloat64(val); ok {
+			key = v
+		} else {
+			switch v := reflect.Indirect(reflect.ValueOf(val)).Interface().(type) {
+			case string:
+				key = v
+			case time.Time:
+				key = v
+			default:
+				return nil, newError("Series.Rank", i, s.name, ErrConversion, fmt.Errorf("can not rank %v", val))
+			}
+		}
+		if len(keys) > 0 && reflect.TypeOf(key) != reflect.TypeOf(keys[0]) {
+			return nil, newError("Series.Rank", i, s.name, ErrConversion, fmt.Errorf("can not rank %v with %v", val, keys[0]))
+		}
+		keys = append(keys, key)
+		positions = append(positions, i)
+	}
+
+	ranks := rankKeys(keys, method, ascending)
+	if pct && len(ranks) > 0 {
+		divisor := float64(len(ranks))
+		if method == RankDense {
+			divisor = floats.Max(ranks)
+		}
+		floats.Scale(1/divisor, ranks)
+	}
+	for i, rank := range ranks {
+		elements[positions[i]] = rank
+	}
+
+	return &Series[float64]{
+		name:     s.name,
+		elements: elements,
+	}, nil
+}
+
+// rankKeys returns 1-based ranks of keys which are all float64, string or time.Time
+func rankKeys(keys []any, method RankMethod, ascending bool) []float64 {
+	return rankBy(len(keys), func(i, j int) int {
+		switch a := keys[i].(type) {
+		case float64:
+			return compareOrdered(a, keys[j].(float64))
+		case string:
+			return compareOrdered(a, keys[j].(string))
+		default:
+			x, y := keys[i].(time.Time), keys[j].(time.Time)
+			if x.Before(y) {
+				return -1
+			} else if x.After(y) {
+				return 1
+			}
+			return 0
+		}
+	}, method, ascending)
+}
+
+func compareOrdered[T float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// rankFloat64s returns 1-based ranks of data
+func rankFloat64s(data []float64, method RankMethod, ascending bool) []float64 {
+	return rankBy(len(data), func(i, j int) int {
+		return compareOrdered(data[i], data[j])
+	}, method, ascending)
+}
+
+// rankBy returns 1-based ranks of n values compared by compare
+func rankBy(n int, compare func(i, j int) int, method RankMethod, ascending bool) []float64 {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if ascending {
+			return compare(order[i], order[j]) < 0
+		}
+		return compare(order[i], order[j]) > 0
+	})
+
+	ranks := make([]float64, n)
+	dense := 0.0
+	for i := 0; i < len(order); {
+		j := i + 1
+		for j < len(order) && compare(order[j], order[i]) == 0 {
+			j++
+		}
+		// order[i:j] are ties which share ranks from i+1 to j
+		dense++
+		for k := i; k < j; k++ {
+			var rank float64
+			switch method {
+			case RankAverage:
+				rank = float64(i+1+j) / 2
+			case RankMin:
+				rank = float64(i + 1)
+			case RankMax:
+				rank = float64(j)
+			case RankFirst:
+				rank = float64(k + 1)
+			case RankDense:
+				rank = dense
+			default:
+				panic("pandat.series.Rank::unsupported method: " + strconv.Itoa(int(method)))
+			}
+			ranks[order[k]] = rank
+		}
+		i = j
+	}
+	return ranks
 }
@@ -1,8 +1,12 @@
 package pandat
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestQuantile(t *testing.T) {
@@ -19,3 +23,105 @@ func TestSum(t *testing.T) {
 	series := NewSeries("test", 1, 2, 3, 4, 5)
 	fmt.Println(series.Sum())
 }
+
+func TestCorr(t *testing.T) {
+	x := NewSeries("x", 1, 2, 3, 4, 5)
+	y := NewSeries("y", 5, 6, 7, 8, 7)
+
+	if v := x.Corr(y, Pearson); math.Abs(v-0.8320502943) > 1e-9 {
+		t.Fatalf("pearson: %v", v)
+	}
+	if v := x.Corr(y, Spearman); math.Abs(v-0.8207826817) > 1e-9 {
+		t.Fatalf("spearman: %v", v)
+	}
+	if v := x.Corr(y, Kendall); math.Abs(v-0.7378647873) > 1e-9 {
+		t.Fatalf("kendall: %v", v)
+	}
+}
+
+func TestValueCounts(t *testing.T) {
+	series := NewSeries[any]("test", "b", "a", "b", nil, "c", "a", "b", math.NaN())
+
+	vc := series.ValueCounts(ValueCountsOption{})
+	values, counts := vc.Index(), vc.Slice()
+	if vc.Name() != "count" || values[0] != "b" || counts[0] != 3 || values[1] != "a" || values[2] != nil || counts[2] != 2 || values[3] != "c" {
+		t.Fatalf("unexpected value counts: %v %v", values, counts)
+	}
+	if count, ok := vc.Loc(math.NaN()); !ok || count != 2 {
+		t.Fatalf("expected 2 nulls, got %v", count)
+	}
+	if count, ok := vc.Loc("a"); !ok || count != 2 {
+		t.Fatalf("expected 2 of a, got %v", count)
+	}
+	if _, ok := vc.Loc("x"); ok {
+		t.Fatalf("expected x not found")
+	}
+
+	vc = series.ValueCounts(ValueCountsOption{NoSort: true})
+	if values := vc.Index(); values[0] != "b" || values[1] != "a" || values[2] != nil || values[3] != "c" {
+		t.Fatalf("expected order of first appearance, got %v", values)
+	}
+
+	vc = series.ValueCounts(ValueCountsOption{DropNa: true, Normalize: true, Ascending: true})
+	if values := vc.Index(); vc.Name() != "proportion" || values[0] != "c" || vc.Get(0) != 1.0/6 {
+		t.Fatalf("unexpected value counts: %v %v", values, vc.Slice())
+	}
+
+	vc = NewSeries("test", 1, 2, 3, 4, 5, 6).ValueCounts(ValueCountsOption{Bins: 2})
+	if counts := vc.Slice(); counts[0] != 3 || counts[1] != 3 {
+		t.Fatalf("unexpected bin counts: %v %v", vc.Index(), counts)
+	}
+	if _, err := series.TryValueCounts(ValueCountsOption{Bins: 2}); !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion, got %v", err)
+	}
+}
+
+func TestModeNull(t *testing.T) {
+	if modes := NewSeries[any]("test", nil, nil, 1.0, math.NaN()).Mode(); len(modes) != 1 || modes[0] != 1.0 {
+		t.Fatalf("expected nulls not counted, got %v", modes)
+	}
+}
+
+func TestModeOrder(t *testing.T) {
+	modes := NewSeries("test", 4, 1, 3, 3, 4, 1).Mode()
+	if len(modes) != 3 || modes[0] != 4 || modes[1] != 1 || modes[2] != 3 {
+		t.Fatalf("unexpected modes: %v", modes)
+	}
+}
+
+func TestRank(t *testing.T) {
+	series := NewSeries[any]("test", 3, 1, 4, 1, nil, 5)
+	cases := map[RankMethod][]float64{
+		RankAverage: {3, 1.5, 4, 1.5, math.NaN(), 5},
+		RankMin:     {3, 1, 4, 1, math.NaN(), 5},
+		RankMax:     {3, 2, 4, 2, math.NaN(), 5},
+		RankFirst:   {3, 1, 4, 2, math.NaN(), 5},
+		RankDense:   {2, 1, 3, 1, math.NaN(), 4},
+	}
+	for method, expected := range cases {
+		ranks := series.Rank(method, true, false).Slice()
+		for i, rank := range ranks {
+			if rank != expected[i] && !(math.IsNaN(rank) && math.IsNaN(expected[i])) {
+				t.Fatalf("method %d: expected %v, got %v", method, expected, ranks)
+			}
+		}
+	}
+
+	if ranks := series.Rank(RankMin, false, true).Slice(); ranks[5] != 0.2 || ranks[1] != 0.8 {
+		t.Fatalf("unexpected pct ranks: %v", ranks)
+	}
+
+	if ranks := NewSeries("test", "b", "a", "c", "a").Rank(RankAverage, true, false).Slice(); !reflect.DeepEqual(ranks, []float64{3, 1.5, 4, 1.5}) {
+		t.Fatalf("unexpected string ranks: %v", ranks)
+	}
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	if ranks := NewSeries("test", day, day.AddDate(0, 0, -1)).Rank(RankMin, true, false).Slice(); !reflect.DeepEqual(ranks, []float64{2, 1}) {
+		t.Fatalf("unexpected time ranks: %v", ranks)
+	}
+	if _, err := NewSeries[any]("test", 1, "a").TryRank(RankMin, true, false); !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion, got %v", err)
+	}
+	if _, err := NewSeries("test", 1, 2).TryRank(RankDense+1, true, false); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package pandat
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -22,3 +23,11 @@ func TestReplace(*testing.T) {
 	fmt.Println(df)
 
 }
+
+func TestTryFloat64(t *testing.T) {
+	_, err := NewSeries[any]("a", "1", "x").TryFloat64()
+	var e *Error
+	if !errors.Is(err, ErrConversion) || !errors.As(err, &e) || e.Row != 1 || e.Column != "a" {
+		t.Fatalf("expected ErrConversion at row 1, got %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+package pandat
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxCell is the type, the style and whether there is a formula of a cell in a worksheet
+type xlsxCell struct {
+	typ     excelize.CellType
+	style   int
+	formula bool
+}
+
+// xlsxPart returns a part of the workbook loaded by excelize, e.g. "xl/workbook.xml", names are case-insensitive
+func xlsxPart(f *excelize.File, name string) ([]byte, bool) {
+	if value, ok := f.Pkg.Load(name); ok {
+		part, ok := value.([]byte)
+		return part, ok
+	}
+	var part []byte
+	f.Pkg.Range(func(key, value any) bool {
+		if k, ok := key.(string); ok && strings.EqualFold(k, name) {
+			part, _ = value.([]byte)
+			return false
+		}
+		return true
+	})
+	return part, part != nil
+}
+
+func decodeXlsxPart(f *excelize.File, name string, v any) error {
+	part, ok := xlsxPart(f, name)
+	if !ok {
+		return fmt.Errorf("%s is not found in workbook", name)
+	}
+	return xml.NewDecoder(bytes.NewReader(part)).Decode(v)
+}
+
+// xlsxSheetPaths returns paths of worksheets by sheet name
+func xlsxSheetPaths(f *excelize.File) (map[string]string, error) {
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXlsxPart(f, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+	paths := make(map[string]string)
+	if f.WorkBook != nil {
+		for _, sheet := range f.WorkBook.Sheets.Sheet {
+			paths[sheet.Name] = targets[sheet.ID]
+		}
+	}
+	return paths, nil
+}
+
+// xlsxDate1904 returns true if dates of the workbook are counted from 1904
+func xlsxDate1904(f *excelize.File) bool {
+	return f.WorkBook != nil && f.WorkBook.WorkbookPr != nil && f.WorkBook.WorkbookPr.Date1904
+}
+
+// xlsxTable is an Excel table of a worksheet
+type xlsxTable struct {
+	sheet      string
+	ref        string
+	headerRows int
+	totalsRows int
+	columns    []string
+}
+
+// findXlsxTable returns the table of given name or display name, or nil if not found.
+// Names are case-insensitive like Excel. The table parts are read from the workbook loaded by excelize,
+// which can add tables but has no API to read them.
+func findXlsxTable(f *excelize.File, name string) (*xlsxTable, error) {
+	paths, err := xlsxSheetPaths(f)
+	if err != nil {
+		return nil, err
+	}
+	for sheet, sheetPath := range paths {
+		var rels struct {
+			Relationships []struct {
+				Type   string `xml:"Type,attr"`
+				Target string `xml:"Target,attr"`
+			} `xml:"Relationship"`
+		}
+		dir, file := path.Split(sheetPath)
+		if err := decodeXlsxPart(f, path.Join(dir, "_rels", file+".rels"), &rels); err != nil {
+			// worksheet without relationships
+			continue
+		}
+		for _, rel := range rels.Relationships {
+			if !strings.HasSuffix(rel.Type, "/table") {
+				continue
+			}
+			var table struct {
+				Name           string `xml:"name,attr"`
+				DisplayName    string `xml:"displayName,attr"`
+				Ref            string `xml:"ref,attr"`
+				HeaderRowCount *int   `xml:"headerRowCount,attr"`
+				TotalsRowCount int    `xml:"totalsRowCount,attr"`
+				Columns        []struct {
+					Name string `xml:"name,attr"`
+				} `xml:"tableColumns>tableColumn"`
+			}
+			target := path.Join(dir, rel.Target)
+			if strings.HasPrefix(rel.Target, "/") {
+				target = strings.TrimPrefix(rel.Target, "/")
+			}
+			if err := decodeXlsxPart(f, target, &table); err != nil {
+				return nil, err
+			}
+			if !strings.EqualFold(table.Name, name) && !strings.EqualFold(table.DisplayName, name) {
+				continue
+			}
+
+			t := &xlsxTable{sheet: sheet, ref: table.Ref, headerRows: 1, totalsRows: table.TotalsRowCount}
+			if table.HeaderRowCount != nil {
+				t.headerRows = *table.HeaderRowCount
+			}
+			for _, column := range table.Columns {
+				t.columns = append(t.columns, column.Name)
+			}
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// readXlsxCells reads types, styles and formulas of cells of a sheet by rows, values are read by excelize.File.GetRows.
+// excelize has GetCellType, GetCellStyle and GetCellFormula for a single cell only, and each of them
+// looks up the cell through all rows of the sheet, so cells are read here in one pass of the worksheet loaded by excelize.
+func readXlsxCells(f *excelize.File, sheet string) ([][]xlsxCell, error) {
+	paths, err := xlsxSheetPaths(f)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := paths[sheet]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s is not exist", sheet)
+	}
+	part, ok := xlsxPart(f, name)
+	if !ok {
+		return nil, fmt.Errorf("%s is not found in workbook", name)
+	}
+
+	var (
+		decoder  = xml.NewDecoder(bytes.NewReader(part))
+		rows     [][]xlsxCell
+		row, col int
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		t, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch t.Name.Local {
+		case "row":
+			row, col = row+1, 0
+			if r := xmlAttr(t, "r"); r != "" {
+				if row, err = strconv.Atoi(r); err != nil {
+					return nil, err
+				}
+			}
+		case "c":
+			col++
+			if r := xmlAttr(t, "r"); r != "" {
+				if col, _, err = excelize.CellNameToCoordinates(r); err != nil {
+					return nil, err
+				}
+			}
+			cell := xlsxCell{typ: excelize.CellTypeNumber}
+			if s := xmlAttr(t, "s"); s != "" {
+				cell.style, _ = strconv.Atoi(s)
+			}
+			switch xmlAttr(t, "t") {
+			case "b":
+				cell.typ = excelize.CellTypeBool
+			case "d":
+				cell.typ = excelize.CellTypeDate
+			case "e":
+				cell.typ = excelize.CellTypeError
+			case "s", "str", "inlineStr":
+				cell.typ = excelize.CellTypeString
+			}
+			for len(rows) < row {
+				rows = append(rows, nil)
+			}
+			for len(rows[row-1]) < col {
+				rows[row-1] = append(rows[row-1], xlsxCell{})
+			}
+			rows[row-1][col-1] = cell
+		case "f":
+			if row > 0 && col > 0 {
+				rows[row-1][col-1].formula = true
+			}
+		}
+	}
+	return rows, nil
+}
+
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// nativeValue returns the raw value of cell as float64, bool, string or time.Time, nil if empty or error
+func nativeValue(value string, cell xlsxCell, dates []bool, date1904 bool) (any, error) {
+	if value == "" {
+		return nil, nil
+	}
+	switch cell.typ {
+	case excelize.CellTypeUnset, excelize.CellTypeError:
+		return nil, nil
+	case excelize.CellTypeBool:
+		return value == "1" || strings.EqualFold(value, "true"), nil
+	case excelize.CellTypeString:
+		return value, nil
+	case excelize.CellTypeDate:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date %q", value)
+	default:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		if cell.style >= 0 && cell.style < len(dates) && dates[cell.style] {
+			return excelize.ExcelDateToTime(v, date1904)
+		}
+		return v, nil
+	}
+}
+
+// dateStyles returns whether each cell style of the workbook is a date or time format
+func dateStyles(f *excelize.File) []bool {
+	if f.Styles == nil || f.Styles.CellXfs == nil {
+		return nil
+	}
+	formats := make(map[int]string)
+	if f.Styles.NumFmts != nil {
+		for _, format := range f.Styles.NumFmts.NumFmt {
+			formats[format.NumFmtID] = format.FormatCode
+		}
+	}
+	dates := make([]bool, len(f.Styles.CellXfs.Xf))
+	for i, xf := range f.Styles.CellXfs.Xf {
+		if xf.NumFmtID == nil {
+			continue
+		}
+		id := *xf.NumFmtID
+		if code, ok := formats[id]; ok {
+			dates[i] = isDateFormat(code)
+		} else {
+			dates[i] = isBuiltInDateFormat(id)
+		}
+	}
+	return dates
+}
+
+// isBuiltInDateFormat returns true if a built-in number format is a date or time format,
+// 27-36 and 50-58 are for east asian locales
+func isBuiltInDateFormat(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+}
+
+// isDateFormat returns true if a number format code has date or time parts,
+// quoted texts, escaped characters and bracketed colors or conditions are ignored
+func isDateFormat(code string) bool {
+	// only the format of positive numbers matters
+	var (
+		section = code
+		quoted  bool
+	)
+	for i, r := range code {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ';' && !quoted {
+			section = code[:i]
+			break
+		}
+	}
+
+	if strings.EqualFold(section, "General") {
+		return false
+	}
+	quoted = false
+	for i := 0; i < len(section); i++ {
+		c := section[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		case c == '[':
+			end := strings.IndexByte(section[i:], ']')
+			if end < 0 {
+				return false
+			}
+			// elapsed time like [h], [mm] and [ss]
+			switch strings.ToLower(section[i+1 : i+end]) {
+			case "h", "hh", "m", "mm", "s", "ss":
+				return true
+			}
+			i += end
+		default:
+			switch c | 0x20 {
+			case 'y', 'm', 'd', 'h', 's':
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// numericValue converts the result of a formula into float64 or bool if possible
+func numericValue(s string) any {
+	if s == "" {
+		return nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(v) {
+		return v
+	}
+	switch s {
+	case "TRUE":
+		return true
+	case "FALSE":
+		return false
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package pandat
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/xuri/excelize/v2"
+	"strings"
+)
+
+// ChartType is the type of an Excel chart
+type ChartType string
+
+const (
+	LineChart ChartType = "line"
+	// BarChart is a chart of vertical bars
+	BarChart ChartType = "col"
+	// HorizontalBarChart is a chart of horizontal bars
+	HorizontalBarChart ChartType = "bar"
+	PieChart           ChartType = "pie"
+	ScatterChart       ChartType = "scatter"
+)
+
+// XlsxChart is a native Excel chart of columns written by XlsxWriter
+type XlsxChart struct {
+	Type  ChartType
+	Title string
+	// Sheet is the sheet of the columns, the chart is inserted into the same sheet
+	Sheet string
+	// Category is the column of labels or x values, rows are numbered if empty
+	Category string
+	// Values are columns of series, named by their headers
+	Values []string
+	// Cell is the top-left cell of the chart, e.g. "H2"
+	Cell string
+	// Width and Height are the size of the chart in pixels, 480 and 290 by default
+	Width  int
+	Height int
+}
+
+// AddChart inserts a chart of columns written into chart.Sheet
+func (w *XlsxWriter) AddChart(chart XlsxChart) error {
+	const op = "XlsxWriter.AddChart"
+	sheet := chart.Sheet
+	if sheet == "" {
+		sheet = defaultXlsxSheet
+	}
+
+	type series struct {
+		Name       string `json:"name"`
+		Categories string `json:"categories,omitempty"`
+		Values     string `json:"values"`
+	}
+	format := struct {
+		Type      ChartType `json:"type"`
+		Series    []series  `json:"series"`
+		Dimension struct {
+			Width  int `json:"width,omitempty"`
+			Height int `json:"height,omitempty"`
+		} `json:"dimension"`
+		Title struct {
+			Name string `json:"name,omitempty"`
+		} `json:"title"`
+		VaryColors bool `json:"vary_colors"`
+	}{Type: chart.Type, VaryColors: chart.Type == PieChart}
+	format.Title.Name = chart.Title
+	format.Dimension.Width, format.Dimension.Height = chart.Width, chart.Height
+
+	var categories string
+	if chart.Category != "" {
+		col, fromRow, toRow, err := w.column(op, sheet, chart.Category)
+		if err != nil {
+			return err
+		}
+		categories = xlsxAreaRef(sheet, col, fromRow, col, toRow)
+	}
+	for _, name := range chart.Values {
+		col, fromRow, toRow, err := w.column(op, sheet, name)
+		if err != nil {
+			return err
+		}
+		if toRow < fromRow {
+			// a chart without values can not be drawn by excel
+			return newError(op, -1, name, ErrIndexOutOfRange, errors.New("no rows to chart"))
+		}
+		format.Series = append(format.Series, series{
+			Name:       xlsxAreaRef(sheet, col, fromRow-1, col, fromRow-1),
+			Categories: categories,
+			Values:     xlsxAreaRef(sheet, col, fromRow, col, toRow),
+		})
+	}
+	if len(format.Series) == 0 {
+		return newError(op, -1, "", ErrColumnNotFound, nil)
+	}
+
+	b, err := json.Marshal(format)
+	if err != nil {
+		return err
+	}
+	return w.file.AddChart(sheet, chart.Cell, string(b))
+}
+
+// xlsxAreaRef returns an absolute reference like 'Sheet1'!$B$2:$B$10
+func xlsxAreaRef(sheet string, fromCol, fromRow, toCol, toRow int) string {
+	from, _ := excelize.CoordinatesToCellName(fromCol, fromRow, true)
+	to, _ := excelize.CoordinatesToCellName(toCol, toRow, true)
+	ref := "'" + strings.ReplaceAll(sheet, "'", "''") + "'!" + from
+	if to != from {
+		ref += ":" + to
+	}
+	return ref
+}
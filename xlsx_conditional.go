@@ -0,0 +1,95 @@
+package pandat
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"strconv"
+)
+
+// ConditionalFormatType is the type of a conditional format
+type ConditionalFormatType int
+
+const (
+	// ColorScale colors cells by their values from MinColor through MidColor to MaxColor
+	ColorScale ConditionalFormatType = iota
+	// DataBar draws a bar of BarColor in each cell by its value
+	DataBar
+	// Highlight applies Style to cells whose values meet Criteria
+	Highlight
+)
+
+// ConditionalFormat is a conditional format of a column written by XlsxWriter
+type ConditionalFormat struct {
+	Type ConditionalFormatType
+	// MinColor, MidColor and MaxColor are colors of ColorScale, e.g. "#F8696B", MidColor is optional
+	MinColor string
+	MidColor string
+	MaxColor string
+	// BarColor is the color of DataBar, e.g. "#638EC6"
+	BarColor string
+	// Criteria of Highlight, one of ">", ">=", "<", "<=", "==", "!=", "between" and "not between"
+	Criteria string
+	// Value is compared with values of cells by Criteria, Value and MaxValue are the bounds of "between"
+	Value    float64
+	MaxValue float64
+	// Style of Highlight, e.g. &excelize.Style{Font: &excelize.Font{Color: "#9C0006"}}
+	Style *excelize.Style
+}
+
+// AddConditionalFormat adds a conditional format to values of a column written into sheet
+func (w *XlsxWriter) AddConditionalFormat(sheet, column string, format ConditionalFormat) error {
+	const op = "XlsxWriter.AddConditionalFormat"
+	if format.Type < ColorScale || format.Type > Highlight {
+		return newError(op, -1, column, ErrInvalidOption, fmt.Errorf("unknown conditional format type %d", format.Type))
+	}
+	col, fromRow, toRow, err := w.column(op, sheet, column)
+	if err != nil || toRow < fromRow {
+		return err
+	}
+	if sheet == "" {
+		sheet = defaultXlsxSheet
+	}
+
+	rule := map[string]any{"criteria": "=", "min_type": "min", "max_type": "max"}
+	switch format.Type {
+	case ColorScale:
+		rule["type"] = "2_color_scale"
+		rule["min_color"], rule["max_color"] = format.MinColor, format.MaxColor
+		if format.MidColor != "" {
+			rule["type"] = "3_color_scale"
+			rule["mid_type"], rule["mid_value"], rule["mid_color"] = "percentile", "50", format.MidColor
+		}
+	case DataBar:
+		rule["type"] = "data_bar"
+		rule["bar_color"] = format.BarColor
+	case Highlight:
+		style := format.Style
+		if style == nil {
+			style = &excelize.Style{}
+		}
+		b, err := json.Marshal(style)
+		if err != nil {
+			return err
+		}
+		id, err := w.file.NewConditionalStyle(string(b))
+		if err != nil {
+			return err
+		}
+		rule = map[string]any{"type": "cell", "criteria": format.Criteria, "format": id}
+		value := strconv.FormatFloat(format.Value, 'f', -1, 64)
+		if format.Criteria == "between" || format.Criteria == "not between" {
+			rule["minimum"], rule["maximum"] = value, strconv.FormatFloat(format.MaxValue, 'f', -1, 64)
+		} else {
+			rule["value"] = value
+		}
+	}
+
+	b, err := json.Marshal([]map[string]any{rule})
+	if err != nil {
+		return err
+	}
+	from, _ := excelize.CoordinatesToCellName(col, fromRow)
+	to, _ := excelize.CoordinatesToCellName(col, toRow)
+	return w.file.SetConditionalFormat(sheet, from+":"+to, string(b))
+}
@@ -0,0 +1,251 @@
+package pandat
+
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSheetNameLength is the maximum number of characters of a sheet name
+const maxSheetNameLength = 31
+
+// WriteStream is like Write but writes rows through a stream writer with bounded memory,
+// rows beyond option.MaxRows or the row limit of Excel are continued in new sheets named like "Sheet1 (2)".
+// The sheets written are replaced and can not be written or formatted any more, ErrStreamedSheet is returned if so.
+func (w *XlsxWriter) WriteStream(df *DataFrame[any], option WriteXlsxOption) error {
+	return w.stream(dataFrameExportColumns(df), option)
+}
+
+// WriteFrameStream is like WriteStream but writes a frame
+func (w *XlsxWriter) WriteFrameStream(f *Frame, option WriteXlsxOption) error {
+	return w.stream(frameExportColumns(f), option)
+}
+
+func (w *XlsxWriter) stream(columns exportColumns, option WriteXlsxOption) error {
+	const op = "XlsxWriter.WriteStream"
+	col, row, err := option.startCoordinates()
+	if err != nil {
+		return err
+	}
+	if option.Index {
+		columns = columns.withIndex(option.IndexLabel)
+	}
+	maxRows := excelize.TotalRows - row
+	if option.MaxRows > 0 && option.MaxRows < maxRows {
+		maxRows = option.MaxRows
+	}
+	if maxRows <= 0 {
+		return newError(op, -1, "", ErrIndexOutOfRange, fmt.Errorf("no rows after start cell %s", option.StartCell))
+	}
+
+	header := make([]any, len(columns.names))
+	headerStyle := 0
+	if option.HeaderStyle != nil {
+		if headerStyle, err = w.file.NewStyle(option.HeaderStyle); err != nil {
+			return err
+		}
+	}
+	for i, name := range columns.names {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: name}
+	}
+	styles, err := w.streamStyles(op, columns, option)
+	if err != nil {
+		return err
+	}
+	var widths []float64
+	if option.AutoFit {
+		widths = streamWidths(columns)
+	}
+
+	base := option.Sheet
+	if base == "" {
+		base = defaultXlsxSheet
+	}
+	values := make([]any, len(columns.names))
+	for n, from := 0, 0; n == 0 || from < columns.nrows; n, from = n+1, from+maxRows {
+		to := from + maxRows
+		if to > columns.nrows {
+			to = columns.nrows
+		}
+		sheet := w.sheet(WriteXlsxOption{Sheet: xlsxSheetName(base, n)})
+		if w.streamed[sheet] {
+			return newError(op, -1, "", ErrStreamedSheet, fmt.Errorf("sheet %q", sheet))
+		}
+		if err := w.streamSheetStyle(sheet, len(columns.names), to-from, col, row, option); err != nil {
+			return err
+		}
+		sw, err := w.file.NewStreamWriter(sheet)
+		if err != nil {
+			return err
+		}
+		for i, width := range widths {
+			if err := sw.SetColWidth(col+i, col+i, width); err != nil {
+				return err
+			}
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(col, row)
+		if err := sw.SetRow(cell, header); err != nil {
+			return err
+		}
+		for nrow := from; nrow < to; nrow++ {
+			for ncol, value := range columns.values {
+				val := xlsxValue(value(nrow))
+				if styles[ncol] != 0 && val != nil {
+					val = excelize.Cell{StyleID: styles[ncol], Value: val}
+				} else if t, ok := val.(time.Time); ok {
+					// the stream writer creates a date style for each time value otherwise
+					val = excelize.Cell{StyleID: styles[len(styles)-1], Value: t}
+				}
+				values[ncol] = val
+			}
+			cell, _ := excelize.CoordinatesToCellName(col, row+nrow-from+1)
+			if err := sw.SetRow(cell, values); err != nil {
+				return err
+			}
+		}
+		if err := sw.Flush(); err != nil {
+			return err
+		}
+		if w.streamed == nil {
+			w.streamed = make(map[string]bool)
+		}
+		w.streamed[sheet] = true
+	}
+	return nil
+}
+
+// streamStyles returns styles of values by column for NumberFormats,
+// followed by the date style of time values
+func (w *XlsxWriter) streamStyles(op string, columns exportColumns, option WriteXlsxOption) ([]int, error) {
+	styles := make([]int, len(columns.names)+1)
+	for name, format := range option.NumberFormats {
+		i := -1
+		for j, columnName := range columns.names {
+			if columnName == name {
+				i = j
+			}
+		}
+		if i < 0 {
+			return nil, newError(op, -1, name, ErrColumnNotFound, nil)
+		}
+		format := format
+		style, err := w.file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+		if err != nil {
+			return nil, err
+		}
+		styles[i] = style
+	}
+	style, err := w.file.NewStyle(&excelize.Style{NumFmt: 22})
+	if err != nil {
+		return nil, err
+	}
+	styles[len(columns.names)] = style
+	return styles, nil
+}
+
+// streamSheetStyle freezes the header and adds the auto filter before rows are streamed,
+// the stream writer keeps them in the worksheet
+func (w *XlsxWriter) streamSheetStyle(sheet string, ncols, nrows, col, row int, option WriteXlsxOption) error {
+	if ncols == 0 {
+		return nil
+	}
+	cellName := func(col, row int) string {
+		name, _ := excelize.CoordinatesToCellName(col, row)
+		return name
+	}
+	if option.FreezeHeader {
+		xSplit, activePane := 0, "bottomLeft"
+		if option.Index {
+			xSplit, activePane = col, "bottomRight"
+		}
+		panes := fmt.Sprintf(`{"freeze":true,"split":false,"x_split":%d,"y_split":%d,"top_left_cell":%q,"active_pane":%q}`,
+			xSplit, row, cellName(xSplit+1, row+1), activePane)
+		if err := w.file.SetPanes(sheet, panes); err != nil {
+			return err
+		}
+	}
+	if option.AutoFilter {
+		if err := w.file.AutoFilter(sheet, cellName(col, row), cellName(col+ncols-1, row+nrows), ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamWidths returns widths of columns fit to headers and values
+func streamWidths(columns exportColumns) []float64 {
+	widths := make([]float64, len(columns.names))
+	for i, value := range columns.values {
+		width := displayWidth(columns.names[i])
+		for nrow := 0; nrow < columns.nrows; nrow++ {
+			if n := displayWidth(cellString(xlsxValue(value(nrow)))); n > width {
+				width = n
+			}
+		}
+		widths[i] = autoFitWidth(width)
+	}
+	return widths
+}
+
+// xlsxSheetName returns the name of the nth sheet split from sheet, e.g. "Sheet1 (2)",
+// sheet is truncated to fit the 31 characters limit of sheet names
+func xlsxSheetName(sheet string, n int) string {
+	if n == 0 {
+		return sheet
+	}
+	suffix := fmt.Sprintf(" (%d)", n+1)
+	for utf8.RuneCountInString(sheet)+len(suffix) > maxSheetNameLength {
+		_, size := utf8.DecodeLastRuneInString(sheet)
+		sheet = sheet[:len(sheet)-size]
+	}
+	return sheet + suffix
+}
+
+// ToXlsxStream writes the dataframe into a new workbook like ToXlsx,
+// but rows are streamed from the columns with bounded memory and split into sheets by option.MaxRows
+func (d *DataFrame[E]) ToXlsxStream(f io.Writer, option WriteXlsxOption) error {
+	w := NewXlsxWriter()
+	if err := w.stream(dataFrameExportColumns(d), option); err != nil {
+		return err
+	}
+	_, err := w.WriteTo(f)
+	return err
+}
+
+func (d *DataFrame[E]) ToXlsxStreamPath(filepath string, option WriteXlsxOption) error {
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if err := d.ToXlsxStream(f, option); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ToXlsxStream is like DataFrame.ToXlsxStream
+func (f *Frame) ToXlsxStream(w io.Writer, option WriteXlsxOption) error {
+	xw := NewXlsxWriter()
+	if err := xw.stream(frameExportColumns(f), option); err != nil {
+		return err
+	}
+	_, err := xw.WriteTo(w)
+	return err
+}
+
+func (f *Frame) ToXlsxStreamPath(filepath string, option WriteXlsxOption) error {
+	out, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if err := f.ToXlsxStream(out, option); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
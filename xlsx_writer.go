@@ -0,0 +1,304 @@
+package pandat
+
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/width"
+	"io"
+	"math"
+	"os"
+)
+
+// defaultXlsxSheet is the sheet created with a new workbook
+const defaultXlsxSheet = "Sheet1"
+
+// XlsxWriter writes dataframes into sheets of one workbook, e.g.
+//
+//	w := NewXlsxWriter()
+//	_ = w.Write(orders, WriteXlsxOption{Sheet: "orders"})
+//	_ = w.Write(summary, WriteXlsxOption{Sheet: "orders", StartCell: "H1"})
+//	_ = w.Write(customers, WriteXlsxOption{Sheet: "customers"})
+//	err := w.Save("report.xlsx")
+type XlsxWriter struct {
+	file *excelize.File
+	// unused is true if the default sheet of a new workbook has not been written,
+	// it is deleted once another sheet is written
+	unused bool
+	// frames are where dataframes are written by sheet, for conditional formats and charts
+	frames map[string][]xlsxFrame
+	// streamed are sheets written by streaming, which can not be modified any more
+	streamed map[string]bool
+}
+
+// xlsxFrame is where a dataframe is written, the header is at column col and row row
+type xlsxFrame struct {
+	col, row int
+	names    []string
+	nrows    int
+}
+
+// NewXlsxWriter returns a writer of a new workbook
+func NewXlsxWriter() *XlsxWriter {
+	return &XlsxWriter{file: excelize.NewFile(), unused: true}
+}
+
+// OpenXlsxWriter returns a writer which appends dataframes to an existing workbook,
+// sheets of the workbook are kept and the cells written are overwritten
+func OpenXlsxWriter(r io.Reader, option ...ReadXlsxOption) (*XlsxWriter, error) {
+	f, err := excelize.OpenReader(r, excelize.Options{Password: firstReadXlsxOption(option).Password})
+	if err != nil {
+		return nil, err
+	}
+	return &XlsxWriter{file: f}, nil
+}
+
+// OpenXlsxWriterPath is like OpenXlsxWriter but opens the workbook of given path
+func OpenXlsxWriterPath(filepath string, option ...ReadXlsxOption) (*XlsxWriter, error) {
+	r, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return OpenXlsxWriter(r, option...)
+}
+
+// Write writes the header and values of df into option.Sheet from option.StartCell,
+// the sheet is created if not exists
+func (w *XlsxWriter) Write(df *DataFrame[any], option WriteXlsxOption) error {
+	return w.write(dataFrameExportColumns(df), option)
+}
+
+// WriteFrame is like Write but writes a frame
+func (w *XlsxWriter) WriteFrame(f *Frame, option WriteXlsxOption) error {
+	return w.write(frameExportColumns(f), option)
+}
+
+func (w *XlsxWriter) write(columns exportColumns, option WriteXlsxOption) error {
+	col, row, err := option.startCoordinates()
+	if err != nil {
+		return err
+	}
+	if option.Index {
+		columns = columns.withIndex(option.IndexLabel)
+	}
+	sheet := w.sheet(option)
+	if w.streamed[sheet] {
+		return newError("XlsxWriter.Write", -1, "", ErrStreamedSheet, fmt.Errorf("sheet %q", sheet))
+	}
+
+	cell, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return err
+	}
+	header := columns.names
+	if err := w.file.SetSheetRow(sheet, cell, &header); err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns.values))
+	for nrow := 0; nrow < columns.nrows; nrow++ {
+		for ncol, value := range columns.values {
+			values[ncol] = xlsxValue(value(nrow))
+		}
+		if cell, err = excelize.CoordinatesToCellName(col, row+nrow+1); err != nil {
+			return err
+		}
+		if err := w.file.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+	if err := w.style(sheet, columns, col, row, option); err != nil {
+		return err
+	}
+	if w.frames == nil {
+		w.frames = make(map[string][]xlsxFrame)
+	}
+	w.frames[sheet] = append(w.frames[sheet], xlsxFrame{col: col, row: row, names: columns.names, nrows: columns.nrows})
+	return nil
+}
+
+// column returns the column number and rows of values of a column written into sheet,
+// the latest dataframe is used if the column is written several times
+func (w *XlsxWriter) column(op, sheet, name string) (col, fromRow, toRow int, err error) {
+	if sheet == "" {
+		sheet = defaultXlsxSheet
+	}
+	if w.streamed[sheet] {
+		return 0, 0, 0, newError(op, -1, name, ErrStreamedSheet, fmt.Errorf("sheet %q", sheet))
+	}
+	frames := w.frames[sheet]
+	for i := len(frames) - 1; i >= 0; i-- {
+		for j, columnName := range frames[i].names {
+			if columnName == name {
+				return frames[i].col + j, frames[i].row + 1, frames[i].row + frames[i].nrows, nil
+			}
+		}
+	}
+	return 0, 0, 0, newError(op, -1, name, ErrColumnNotFound, nil)
+}
+
+// DefaultHeaderStyle returns a bold style with a gray background and a bottom border for WriteXlsxOption.HeaderStyle
+func DefaultHeaderStyle() *excelize.Style {
+	return &excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#D9D9D9"}},
+		Border:    []excelize.Border{{Type: "bottom", Color: "#000000", Style: 1}},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	}
+}
+
+// style applies styles of option to columns written at column col and row row
+func (w *XlsxWriter) style(sheet string, columns exportColumns, col, row int, option WriteXlsxOption) error {
+	if len(columns.names) == 0 {
+		return nil
+	}
+	var (
+		lastCol = col + len(columns.names) - 1
+		lastRow = row + columns.nrows
+	)
+	cellName := func(col, row int) string {
+		name, _ := excelize.CoordinatesToCellName(col, row)
+		return name
+	}
+
+	if option.HeaderStyle != nil {
+		style, err := w.file.NewStyle(option.HeaderStyle)
+		if err != nil {
+			return err
+		}
+		if err := w.file.SetCellStyle(sheet, cellName(col, row), cellName(lastCol, row), style); err != nil {
+			return err
+		}
+	}
+
+	for name, format := range option.NumberFormats {
+		i := columns.index(name)
+		if i < 0 {
+			return newError("XlsxWriter.Write", -1, name, ErrColumnNotFound, nil)
+		}
+		if columns.nrows == 0 {
+			continue
+		}
+		format := format
+		style, err := w.file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+		if err != nil {
+			return err
+		}
+		if err := w.file.SetCellStyle(sheet, cellName(col+i, row+1), cellName(col+i, lastRow), style); err != nil {
+			return err
+		}
+	}
+
+	if option.AutoFit {
+		for i, width := range streamWidths(columns) {
+			name, _ := excelize.ColumnNumberToName(col + i)
+			if err := w.file.SetColWidth(sheet, name, name, width); err != nil {
+				return err
+			}
+		}
+	}
+
+	if option.FreezeHeader {
+		xSplit := 0
+		if option.Index {
+			xSplit = col
+		}
+		activePane := "bottomLeft"
+		if xSplit > 0 {
+			activePane = "bottomRight"
+		}
+		panes := fmt.Sprintf(`{"freeze":true,"split":false,"x_split":%d,"y_split":%d,"top_left_cell":%q,"active_pane":%q}`,
+			xSplit, row, cellName(xSplit+1, row+1), activePane)
+		if err := w.file.SetPanes(sheet, panes); err != nil {
+			return err
+		}
+	}
+
+	if option.AutoFilter {
+		if err := w.file.AutoFilter(sheet, cellName(col, row), cellName(lastCol, lastRow), ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayWidth returns the width of s in a monospaced font, east asian wide and fullwidth characters count twice
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// autoFitWidth returns the column width for text of given display width, within the default width and the maximum width 255
+func autoFitWidth(n int) float64 {
+	return math.Min(math.Max(float64(n)+2, 8.43), 255)
+}
+
+// sheet returns the name of the sheet to write, which is created if not exists
+func (w *XlsxWriter) sheet(option WriteXlsxOption) string {
+	sheet := option.Sheet
+	if sheet == "" {
+		sheet = defaultXlsxSheet
+	}
+	if w.file.GetSheetIndex(sheet) < 0 {
+		w.file.NewSheet(sheet)
+		if w.unused {
+			// the default sheet is not needed any more
+			w.file.DeleteSheet(defaultXlsxSheet)
+			w.file.SetActiveSheet(w.file.GetSheetIndex(sheet))
+		}
+	}
+	w.unused = false
+	return sheet
+}
+
+// WriteTo writes the workbook to out
+func (w *XlsxWriter) WriteTo(out io.Writer) (int64, error) {
+	return w.file.WriteTo(out)
+}
+
+// Save writes the workbook to the file of given path
+func (w *XlsxWriter) Save(filepath string) error {
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if _, err := w.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// startCoordinates returns the column and the row of StartCell, "A1" by default
+func (o WriteXlsxOption) startCoordinates() (int, int, error) {
+	if o.StartCell == "" {
+		return 1, 1, nil
+	}
+	col, row, err := excelize.CellNameToCoordinates(o.StartCell)
+	if err != nil {
+		return 0, 0, newError("XlsxWriter.Write", -1, "", ErrInvalidExpr, err)
+	}
+	return col, row, nil
+}
+
+// xlsxValue returns the value written into a cell, null values are empty cells
+func xlsxValue(val any) any {
+	if isNull(val) {
+		return nil
+	}
+	if v, ok := val.(float32); ok && math.IsInf(float64(v), 0) {
+		return nil
+	}
+	if v, ok := val.(float64); ok && math.IsInf(v, 0) {
+		return nil
+	}
+	return val
+}
@@ -0,0 +1,285 @@
+package pandat
+
+import (
+	"bytes"
+	"errors"
+	"github.com/xuri/excelize/v2"
+	"html"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXlsxWriter(t *testing.T) {
+	orders := NewDataFrame(NewSeries[any]("id", int64(1), int64(2)), NewSeries[any]("amount", 1.5, math.NaN()))
+	summary := NewDataFrame(NewSeries[any]("total", 1.5))
+
+	w := NewXlsxWriter()
+	if err := w.Write(orders, WriteXlsxOption{Sheet: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(summary, WriteXlsxOption{Sheet: "orders", StartCell: "D2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(summary, WriteXlsxOption{Sheet: "summary"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dfs, err := ReadXlsxAll(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Range: "A:B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dfs) != 2 || dfs["orders"] == nil || dfs["summary"] == nil {
+		t.Fatalf("expected sheets orders and summary only, got %v", dfs)
+	}
+	if df := dfs["orders"]; df.NRows() != 2 || df.Val(0, "id") != int64(1) || !isNull(df.Val(1, "amount")) {
+		t.Fatalf("unexpected orders: %v", df.Seriess().Slice())
+	}
+	df, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Sheet: "orders", Range: "D2:D3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(df.Names(), []string{"total"}) || df.Val(0, "total") != 1.5 {
+		t.Fatalf("unexpected summary: %v", df.Seriess().Slice())
+	}
+
+	// append a sheet to the existing workbook
+	w, err = OpenXlsxWriter(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(orders, WriteXlsxOption{Sheet: "appended"}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dfs, err = ReadXlsxAll(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Range: "A:B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dfs) != 3 || dfs["appended"].NRows() != 2 {
+		t.Fatalf("unexpected sheets: %v", dfs)
+	}
+}
+
+func TestXlsxWriterStyle(t *testing.T) {
+	df := NewDataFrame(
+		NewSeries[any]("name", "北京市朝阳区", "abc"),
+		NewSeries[any]("code", "abcdefghijkl", "x"),
+		NewSeries[any]("amount", 1234.5, 2.0),
+	)
+	w := NewXlsxWriter()
+	err := w.Write(df, WriteXlsxOption{
+		Sheet:         "report",
+		HeaderStyle:   DefaultHeaderStyle(),
+		AutoFit:       true,
+		NumberFormats: map[string]string{"amount": "#,##0.00"},
+		FreezeHeader:  true,
+		AutoFilter:    true,
+		Index:         true,
+		IndexLabel:    "no",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := w.file
+
+	if names := f.GetSheetList(); !reflect.DeepEqual(names, []string{"report"}) {
+		t.Fatalf("unexpected sheets: %v", names)
+	}
+	if header, _ := f.GetRows("report"); !reflect.DeepEqual(header[0], []string{"no", "name", "code", "amount"}) || header[2][0] != "1" {
+		t.Fatalf("unexpected rows: %v", header)
+	}
+	if style, _ := f.GetCellStyle("report", "D1"); style == 0 {
+		t.Fatalf("expected header style")
+	}
+	style, _ := f.GetCellStyle("report", "D2")
+	var format string
+	for _, numFmt := range f.Styles.NumFmts.NumFmt {
+		if numFmt.NumFmtID == *f.Styles.CellXfs.Xf[style].NumFmtID {
+			format = numFmt.FormatCode
+		}
+	}
+	if format != "#,##0.00" {
+		t.Fatalf("expected number format of amount, got %q", format)
+	}
+	// 6 wide characters are wider than 12 narrow characters
+	nameWidth, _ := f.GetColWidth("report", "B")
+	codeWidth, _ := f.GetColWidth("report", "C")
+	if nameWidth != 14 || codeWidth != 14 {
+		t.Fatalf("unexpected widths: %v %v", nameWidth, codeWidth)
+	}
+	var filter bool
+	for _, name := range f.GetDefinedName() {
+		filter = filter || name.Name == "_xlnm._FilterDatabase"
+	}
+	if !filter {
+		t.Fatalf("expected autofilter")
+	}
+
+	if err := w.Write(df, WriteXlsxOption{NumberFormats: map[string]string{"missing": "0"}}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestXlsxWriterChart(t *testing.T) {
+	df := NewDataFrame(
+		NewSeries[any]("month", "Jan", "Feb", "Mar"),
+		NewSeries[any]("sales", 10.0, 20.0, 15.0),
+		NewSeries[any]("cost", 8.0, 12.0, 9.0),
+	)
+	w := NewXlsxWriter()
+	if err := w.Write(df, WriteXlsxOption{Sheet: "it's", StartCell: "B2"}); err != nil {
+		t.Fatal(err)
+	}
+	formats := []ConditionalFormat{
+		{Type: ColorScale, MinColor: "#F8696B", MidColor: "#FFEB84", MaxColor: "#63BE7B"},
+		{Type: DataBar, BarColor: "#638EC6"},
+		{Type: Highlight, Criteria: ">", Value: 12, Style: &excelize.Style{Font: &excelize.Font{Color: "#9C0006"}}},
+		{Type: Highlight, Criteria: "between", Value: 9, MaxValue: 11},
+	}
+	for _, format := range formats {
+		if err := w.AddConditionalFormat("it's", "sales", format); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := w.AddChart(XlsxChart{Type: LineChart, Title: "Sales", Sheet: "it's", Category: "month", Values: []string{"sales", "cost"}, Cell: "G2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddChart(XlsxChart{Type: PieChart, Sheet: "it's", Values: []string{"missing"}, Cell: "G20"}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+	if err := w.AddConditionalFormat("", "sales", ConditionalFormat{Type: DataBar}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+	if err := w.AddConditionalFormat("it's", "sales", ConditionalFormat{Type: Highlight + 1}); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+
+	// a dataframe without rows can not be charted and has no conditional format
+	empty := NewXlsxWriter()
+	if err := empty.Write(NewDataFrame(NewSeries[any]("month"), NewSeries[any]("sales")), WriteXlsxOption{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := empty.AddChart(XlsxChart{Type: LineChart, Category: "month", Values: []string{"sales"}, Cell: "D2"}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := empty.AddConditionalFormat("", "sales", ConditionalFormat{Type: DataBar}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := xlsxSheetPaths(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		part, ok := xlsxPart(f, name)
+		if !ok {
+			t.Fatalf("%s not found", name)
+		}
+		return string(part)
+	}
+	sheet := read(paths["it's"])
+	for _, s := range []string{`sqref="C3:C5"`, "colorScale", "dataBar", `operator="greaterThan"`, `operator="between"`} {
+		if !strings.Contains(sheet, s) {
+			t.Fatalf("expected %s in sheet", s)
+		}
+	}
+	chart := html.UnescapeString(read("xl/charts/chart1.xml"))
+	for _, s := range []string{"'it''s'!$C$3:$C$5", "'it''s'!$D$2", "'it''s'!$B$3:$B$5"} {
+		if !strings.Contains(chart, s) {
+			t.Fatalf("expected %s in chart", s)
+		}
+	}
+}
+
+func TestXlsxWriterStream(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	df := NewDataFrame(
+		NewSeries[any]("id", int64(1), int64(2), int64(3), int64(4), int64(5)),
+		NewSeries[any]("amount", 1.5, math.NaN(), 3.0, 4.0, 5.0),
+		NewSeries[any]("day", day, day, day, nil, day),
+	)
+	var buf bytes.Buffer
+	err := df.ToXlsxStream(&buf, WriteXlsxOption{
+		Sheet:         "data",
+		MaxRows:       2,
+		HeaderStyle:   DefaultHeaderStyle(),
+		NumberFormats: map[string]string{"amount": "0.00"},
+		AutoFit:       true,
+		FreezeHeader:  true,
+		AutoFilter:    true,
+		Index:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dfs, err := ReadXlsxAll(bytes.NewReader(buf.Bytes()), ReadXlsxOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dfs) != 3 {
+		t.Fatalf("expected 3 sheets, got %d", len(dfs))
+	}
+	for sheet, nrows := range map[string]int{"data": 2, "data (2)": 2, "data (3)": 1} {
+		if dfs[sheet] == nil || dfs[sheet].NRows() != nrows {
+			t.Fatalf("unexpected sheet %s: %v", sheet, dfs[sheet])
+		}
+	}
+	if ids := dfs["data (2)"].Get("id").Slice(); !reflect.DeepEqual(ids, []any{int64(3), int64(4)}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+	if amount := dfs["data"].Val(1, "amount"); !isNull(amount) {
+		t.Fatalf("expected null amount, got %v", amount)
+	}
+	if index := dfs["data (3)"].Val(0, ""); index != int64(4) {
+		t.Fatalf("expected index 4, got %v", index)
+	}
+
+	f := ReadSliceTyped([][]string{{"id", "1", "2", "3"}, {"name", "a", "b", "c"}}, true)
+	w := NewXlsxWriter()
+	if err := w.WriteFrameStream(f, WriteXlsxOption{StartCell: "B2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddConditionalFormat("", "id", ConditionalFormat{Type: DataBar}); !errors.Is(err, ErrStreamedSheet) {
+		t.Fatalf("expected ErrStreamedSheet, got %v", err)
+	}
+	if err := w.Write(df, WriteXlsxOption{StartCell: "H1"}); !errors.Is(err, ErrStreamedSheet) {
+		t.Fatalf("expected ErrStreamedSheet, got %v", err)
+	}
+	if err := w.WriteStream(df, WriteXlsxOption{Sheet: "last", StartCell: "A1048576"}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	buf.Reset()
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadXlsx(bytes.NewReader(buf.Bytes()), ReadXlsxOption{Range: "B2:C5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := got.Get("name").Slice(); !reflect.DeepEqual(names, []any{"a", "b", "c"}) {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	if name := xlsxSheetName("abcdefghijklmnopqrstuvwxyz01234", 9); name != "abcdefghijklmnopqrstuvwxyz (10)" {
+		t.Fatalf("unexpected sheet name: %s", name)
+	}
+}